@@ -0,0 +1,228 @@
+// cmd/artifacts.go
+//
+// Locations and metadata of the draft artifacts kept in .git/pr_buddy_db
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/revisions"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// commitArtifactDir returns .git/pr_buddy_db/<branch>/commit-<sha7> for the given commit.
+func commitArtifactDir(branch, commitHash string) (string, error) {
+	if len(commitHash) < 7 {
+		return "", fmt.Errorf("invalid commit hash %q", commitHash)
+	}
+
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return "", fmt.Errorf("repo path detection: %w", err)
+	}
+
+	return filepath.Join(
+		repoPath,
+		".git", "pr_buddy_db",
+		utils.SanitizeBranchName(branch),
+		fmt.Sprintf("commit-%s", commitHash[:7]),
+	), nil
+}
+
+// branchArtifactDir returns .git/pr_buddy_db/<branch>/branch, where branch-level drafts live.
+func branchArtifactDir(branch string) (string, error) {
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return "", fmt.Errorf("repo path detection: %w", err)
+	}
+
+	return filepath.Join(
+		repoPath,
+		".git", "pr_buddy_db",
+		utils.SanitizeBranchName(branch),
+		"branch",
+	), nil
+}
+
+const (
+	draftScopeCommit = "commit"
+	draftScopeBranch = "branch"
+)
+
+// draftMeta is saved as draft_meta.json next to every draft.md.
+type draftMeta struct {
+	Scope       string `json:"scope"`
+	Branch      string `json:"branch"`
+	Head        string `json:"head"`
+	Base        string `json:"base,omitempty"`
+	MergeBase   string `json:"merge_base,omitempty"`
+	Commits     int    `json:"commits,omitempty"`
+	GeneratedAt string `json:"generated_at"`
+	Revision    int    `json:"revision,omitempty"`
+	*llm.DraftResult
+}
+
+// draftFiles are the artifacts kept for every revision of a draft.
+var draftFiles = []string{"draft.md", "draft.json", "draft_meta.json"}
+
+// snapshotUnversionedDraft keeps a draft saved before drafts had revisions as
+// revision 1, so the next regeneration doesn't overwrite it.
+func snapshotUnversionedDraft(dir string) error {
+	if !utils.FileExists(filepath.Join(dir, "draft.md")) {
+		return nil
+	}
+	revs, err := revisions.List(dir)
+	if err != nil || len(revs) > 0 {
+		return err
+	}
+	return revisions.Save(dir, 1, draftFiles)
+}
+
+// currentRevision returns the revision the top-level draft in dir was saved as;
+// for drafts without a recorded revision that is the latest one.
+func currentRevision(dir string, revs []revisions.Revision) int {
+	if meta, err := readDraftMeta(dir); err == nil && meta.Revision > 0 {
+		return meta.Revision
+	}
+	if len(revs) == 0 {
+		return 0
+	}
+	return revs[len(revs)-1].Number
+}
+
+// readDraftMeta loads draft_meta.json from dir.
+func readDraftMeta(dir string) (*draftMeta, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "draft_meta.json"))
+	if err != nil {
+		return nil, err
+	}
+	var meta draftMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("invalid draft metadata: %w", err)
+	}
+	return &meta, nil
+}
+
+// readStructuredDraft loads draft.json from dir; it only exists when the model
+// produced a valid structured draft.
+func readStructuredDraft(dir string) (*llm.StructuredDraft, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "draft.json"))
+	if err != nil {
+		return nil, err
+	}
+	var draft llm.StructuredDraft
+	if err := json.Unmarshal(data, &draft); err != nil {
+		return nil, fmt.Errorf("invalid structured draft: %w", err)
+	}
+	return &draft, nil
+}
+
+// branchDraftIsCurrent reports whether the branch-level draft was generated at head.
+func branchDraftIsCurrent(branch, head string) bool {
+	dir, err := branchArtifactDir(branch)
+	if err != nil {
+		return false
+	}
+	meta, err := readDraftMeta(dir)
+	return err == nil && meta.Head == head && utils.FileExists(filepath.Join(dir, "draft.md"))
+}
+
+// findCommitDraftDir returns the artifact directory holding a draft for commitHash,
+// looking under branch first and then under every other branch, since a commit
+// drafted on a feature branch keeps its draft there after being merged.
+func findCommitDraftDir(branch, commitHash string) (string, bool) {
+	if dir, err := commitArtifactDir(branch, commitHash); err == nil && utils.FileExists(filepath.Join(dir, "draft.md")) {
+		return dir, true
+	}
+	if len(commitHash) < 7 {
+		return "", false
+	}
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return "", false
+	}
+	matches, _ := filepath.Glob(filepath.Join(
+		repoPath, ".git", "pr_buddy_db", "*", fmt.Sprintf("commit-%s", commitHash[:7]), "draft.md"))
+	if len(matches) == 0 {
+		return "", false
+	}
+	return filepath.Dir(matches[0]), true
+}
+
+// prSync is saved as pr.json next to a branch's drafts whenever its PR is created
+// or updated, so 'pr update' can tell which commits are new. Base links a stacked
+// PR to the branch below it.
+type prSync struct {
+	Number   int    `json:"number"`
+	URL      string `json:"url"`
+	Head     string `json:"head"`
+	Base     string `json:"base,omitempty"`
+	SyncedAt string `json:"synced_at"`
+}
+
+func prSyncPath(branch string) (string, error) {
+	dir, err := branchArtifactDir(branch)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dir), "pr.json"), nil
+}
+
+// readPRSync loads the record of the last PR sync of branch.
+func readPRSync(branch string) (*prSync, error) {
+	path, err := prSyncPath(branch)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sync prSync
+	if err := json.Unmarshal(data, &sync); err != nil {
+		return nil, fmt.Errorf("invalid PR sync record: %w", err)
+	}
+	return &sync, nil
+}
+
+// writePRSync records that branch's PR now reflects sync.Head.
+func writePRSync(branch string, sync prSync) error {
+	path, err := prSyncPath(branch)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	sync.SyncedAt = time.Now().UTC().Format(time.RFC3339)
+	data, err := utils.MarshalJSON(sync)
+	if err != nil {
+		return err
+	}
+	return utils.WriteFile(path, []byte(data))
+}
+
+// saveDraftRevision stores draft, changed by hand or in conversation (mode), as a new
+// revision of the draft meta describes, keeping how the original was produced for reference.
+func saveDraftRevision(meta *draftMeta, draft, mode string) error {
+	result := *meta.DraftResult
+	result.Draft = draft
+	result.Mode = mode
+	// The structured draft no longer matches; pr create takes the title from the markdown
+	result.Structured = nil
+	result.DraftTokens = contextpkg.EstimateTokens(draft)
+
+	var input llm.DraftInput
+	if meta.Scope == draftScopeBranch {
+		input.Base, input.MergeBase, input.Commits = meta.Base, meta.MergeBase, meta.Commits
+	}
+	_, err := saveArtifacts(meta.Branch, meta.Head, input, &result)
+	return err
+}
@@ -0,0 +1,190 @@
+// cmd/changelog.go
+//
+// Release notes from the PR drafts stored for every commit in a range.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/changelog"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	changelogFrom       string
+	changelogTo         string
+	changelogFormat     string
+	changelogVersion    string
+	changelogWrite      bool
+	changelogFile       string
+	changelogNoGenerate bool
+	changelogAll        bool
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Generate a changelog from the stored PR drafts of a commit range",
+	Long: `Walks the commits in --from..--to, describes each one with the PR draft stored in
+.git/pr_buddy_db (generating drafts that are missing, unless --no-generate), groups them by
+Conventional Commit type and prints Keep a Changelog markdown or JSON.
+docs, style, test, build, ci and chore commits are left out unless --all or breaking.`,
+	Run: runChangelog,
+}
+
+func init() {
+	changelogCmd.Flags().StringVar(&changelogFrom, "from", "", "Start of the range, exclusive (default: latest tag before --to)")
+	changelogCmd.Flags().StringVar(&changelogTo, "to", "HEAD", "End of the range, inclusive")
+	changelogCmd.Flags().StringVar(&changelogFormat, "format", "markdown", "Output format: markdown or json")
+	changelogCmd.Flags().StringVar(&changelogVersion, "version", changelog.DefaultVersion, "Version heading for the release")
+	changelogCmd.Flags().BoolVar(&changelogWrite, "write", false, "Prepend the release to the changelog file instead of printing it")
+	changelogCmd.Flags().StringVar(&changelogFile, "file", "CHANGELOG.md", "Changelog file for --write, relative to the repository root")
+	changelogCmd.Flags().BoolVar(&changelogNoGenerate, "no-generate", false, "Don't call the LLM for commits without a stored draft")
+	changelogCmd.Flags().BoolVar(&changelogAll, "all", false, "Include docs, style, test, build, ci and chore commits")
+	rootCmd.AddCommand(changelogCmd)
+}
+
+func runChangelog(cmd *cobra.Command, args []string) {
+	if changelogFormat != "markdown" && changelogFormat != "json" {
+		fmt.Printf("[PRBuddy-Go] Error: unknown format %q (use markdown or json)\n", changelogFormat)
+		return
+	}
+	if changelogWrite && changelogFormat != "markdown" {
+		fmt.Println("[PRBuddy-Go] Error: --write only supports the markdown format")
+		return
+	}
+
+	release, err := buildRelease()
+	if err != nil {
+		fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+		return
+	}
+
+	if changelogFormat == "json" {
+		out, err := utils.MarshalJSON(release)
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		fmt.Println(out)
+		return
+	}
+	if !changelogWrite {
+		fmt.Println(release.Markdown())
+		return
+	}
+
+	path := changelogFile
+	if !filepath.IsAbs(path) {
+		repoPath, err := utils.GetRepoPath()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		path = filepath.Join(repoPath, path)
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(path, []byte(changelog.Prepend(string(existing), release.Markdown())), 0644); err != nil {
+		fmt.Printf("[PRBuddy-Go] Error: could not write %s: %v\n", path, err)
+		return
+	}
+	fmt.Printf("[PRBuddy-Go] Added %s to %s\n", release.Heading(), path)
+}
+
+// buildRelease collects an entry per non-merge commit in the range, oldest first.
+func buildRelease() (*changelog.Release, error) {
+	release := &changelog.Release{Version: changelogVersion, From: changelogFrom, To: changelogTo}
+	if release.From == "" {
+		// The latest tag strictly before --to, so a tagged --to still gets its own notes
+		if tag, err := utils.ExecGit("describe", "--tags", "--abbrev=0", changelogTo+"^"); err == nil {
+			release.From = tag
+		}
+	}
+	if release.Version != changelog.DefaultVersion {
+		if date, err := utils.ExecGit("log", "-1", "--format=%cs", changelogTo); err == nil {
+			release.Date = date
+		}
+	}
+
+	revRange := changelogTo
+	if release.From != "" {
+		revRange = release.From + ".." + changelogTo
+	}
+	// %x1e separates commits, %x00 separates the hash from the message
+	log, err := utils.ExecGit("log", "--reverse", "--no-merges", "--format=%H%x00%B%x1e", revRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits in %s: %w", revRange, err)
+	}
+
+	branch, _ := utils.GetCurrentBranch()
+	var entries []changelog.Entry
+	for _, record := range strings.Split(log, "\x1e") {
+		hash, message, ok := strings.Cut(strings.TrimSpace(record), "\x00")
+		if !ok {
+			continue
+		}
+		entry := changelog.NewEntry(hash, message)
+		if title, summary, ok := commitDraftText(strings.TrimSpace(branch), hash); ok {
+			entry.SetDraft(title, summary)
+		}
+		entries = append(entries, entry)
+	}
+	fmt.Fprintf(os.Stderr, "[PRBuddy-Go] %d commit(s) in %s\n", len(entries), revRange)
+
+	release.Build(entries, changelogAll)
+	return release, nil
+}
+
+// commitDraftText returns the title and summary of the stored draft for hash,
+// generating and saving one first when it is missing.
+func commitDraftText(branch, hash string) (string, string, bool) {
+	dir, ok := findCommitDraftDir(branch, hash)
+	if !ok {
+		if changelogNoGenerate || branch == "" || branch == "HEAD" {
+			return "", "", false
+		}
+		var err error
+		if dir, err = generateCommitDraft(branch, hash); err != nil {
+			fmt.Fprintf(os.Stderr, "[PRBuddy-Go] Warning: no draft for %s, using the commit subject: %v\n", hash[:7], err)
+			return "", "", false
+		}
+	}
+
+	if structured, err := readStructuredDraft(dir); err == nil {
+		return structured.Title, structured.Summary, true
+	}
+	draft, err := os.ReadFile(filepath.Join(dir, "draft.md"))
+	if err != nil {
+		return "", "", false
+	}
+	title, summary := changelog.DraftText(string(draft))
+	return title, summary, true
+}
+
+// generateCommitDraft drafts a single commit and saves it like the post-commit hook would.
+func generateCommitDraft(branch, hash string) (string, error) {
+	fmt.Fprintf(os.Stderr, "[PRBuddy-Go] Generating draft for commit %s...\n", hash[:7])
+	input, err := llm.CommitDraftInput(hash)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(input.Diff) == "" {
+		return "", fmt.Errorf("no detectable changes")
+	}
+	result, err := llm.GenerateDraft(context.Background(), input)
+	if err != nil {
+		return "", err
+	}
+	result.Draft = strings.TrimSpace(utils.StripOuterMarkdownCodeFence(result.Draft))
+	return saveArtifacts(branch, hash, input, result)
+}
@@ -0,0 +1,87 @@
+// cmd/commit_msg.go
+//
+// Commit message suggestions for the staged changes. Run by the prepare-commit-msg
+// hook, so every failure is reported and swallowed: a commit is never blocked.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+var commitMsgCmd = &cobra.Command{
+	Use:   "commit-msg [message-file [source [sha]]]",
+	Short: "Suggest a commit message for the staged changes",
+	Long: `Asks the LLM for a commit message describing 'git diff --cached', wrapped at 72 columns and
+following Conventional Commits when the repository's recent history does.
+Without arguments the message is printed. With the arguments git passes to a prepare-commit-msg
+hook it is written into the message file, unless the commit already has a message (-m, -F,
+--amend, merges, squashes, templates). Errors and timeouts only print a warning.`,
+	Args: cobra.MaximumNArgs(3),
+	Run:  runCommitMsg,
+}
+
+func init() {
+	rootCmd.AddCommand(commitMsgCmd)
+}
+
+func runCommitMsg(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		message, err := llm.GenerateCommitMessage(context.Background())
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Could not suggest a commit message: %v\n", err)
+			return
+		}
+		fmt.Println(message)
+		return
+	}
+
+	messageFile := args[0]
+	if len(args) > 1 && args[1] != "" {
+		// git already has a message for this commit
+		return
+	}
+	existing, err := os.ReadFile(messageFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[PRBuddy-Go] Skipping commit message: %v\n", err)
+		return
+	}
+	if hasMessage(string(existing)) {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "[PRBuddy-Go] Suggesting a commit message...")
+	message, err := llm.GenerateCommitMessage(context.Background())
+	if err != nil {
+		if !errors.Is(err, llm.ErrNothingStaged) {
+			fmt.Fprintf(os.Stderr, "[PRBuddy-Go] Could not suggest a commit message: %v\n", err)
+		}
+		return
+	}
+
+	// Keep git's instructions and status comments below the suggestion
+	content := message + "\n" + string(existing)
+	if !strings.HasPrefix(string(existing), "\n") {
+		content = message + "\n\n" + string(existing)
+	}
+	if err := os.WriteFile(messageFile, []byte(content), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "[PRBuddy-Go] Could not write commit message: %v\n", err)
+	}
+}
+
+// hasMessage reports whether a commit message file has any non-comment text.
+func hasMessage(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+	return false
+}
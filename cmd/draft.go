@@ -0,0 +1,237 @@
+// cmd/draft.go
+//
+// Browse and restore the revisions kept for every regenerated draft.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/revisions"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	draftCommit      string
+	draftBranchLevel bool
+)
+
+var draftCmd = &cobra.Command{
+	Use:   "draft",
+	Short: "Inspect and restore revisions of a saved PR draft",
+	Long: `Every generation of a draft ('generate pr', 'post-commit --force') is kept as a numbered
+revision next to the current draft.md. These commands work on the draft of HEAD, of --commit,
+or with --branch on the branch-level draft.`,
+}
+
+var draftHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the revisions of the draft",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, revs, err := draftRevisions()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		current := currentRevision(dir, revs)
+		for _, rev := range revs {
+			marker := " "
+			if rev.Number == current {
+				marker = green("*")
+			}
+			fmt.Printf("%s %s  %s\n", marker, bold(fmt.Sprintf("%3d", rev.Number)), describeRevision(rev.Path))
+		}
+	},
+}
+
+var draftShowCmd = &cobra.Command{
+	Use:   "show [revision]",
+	Short: "Print a revision of the draft (default: the current one)",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, revs, err := draftRevisions()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		path := filepath.Join(dir, "draft.md")
+		if len(args) == 1 {
+			n, err := revisionArg(args[0], revs)
+			if err != nil {
+				fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+				return
+			}
+			path = filepath.Join(revisions.Path(dir, n), "draft.md")
+		}
+		draft, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		fmt.Print(string(draft))
+	},
+}
+
+var draftDiffCmd = &cobra.Command{
+	Use:   "diff <revision> [revision]",
+	Short: "Show how two revisions differ (default: against the current one)",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, revs, err := draftRevisions()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		from, err := revisionArg(args[0], revs)
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		to := currentRevision(dir, revs)
+		if len(args) == 2 {
+			if to, err = revisionArg(args[1], revs); err != nil {
+				fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+				return
+			}
+		}
+
+		out, err := diffFiles(dir,
+			filepath.Join(revisions.Dir, filepath.Base(revisions.Path(dir, from)), "draft.md"),
+			filepath.Join(revisions.Dir, filepath.Base(revisions.Path(dir, to)), "draft.md"),
+		)
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		if out == "" {
+			fmt.Printf("[PRBuddy-Go] Revisions %d and %d are identical\n", from, to)
+			return
+		}
+		fmt.Print(out)
+	},
+}
+
+var draftRestoreCmd = &cobra.Command{
+	Use:   "restore <revision>",
+	Short: "Make an earlier revision the current draft",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, revs, err := draftRevisions()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		n, err := revisionArg(args[0], revs)
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		if err := revisions.Restore(dir, n, draftFiles); err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		fmt.Printf("[PRBuddy-Go] Revision %d is now the current draft: %s\n", n, filepath.Join(dir, "draft.md"))
+	},
+}
+
+func init() {
+	draftCmd.PersistentFlags().StringVar(&draftCommit, "commit", "HEAD", "Commit whose draft to use")
+	draftCmd.PersistentFlags().BoolVar(&draftBranchLevel, "branch", false, "Use the branch-level draft of the current branch")
+	draftCmd.AddCommand(draftHistoryCmd, draftShowCmd, draftDiffCmd, draftRestoreCmd)
+	rootCmd.AddCommand(draftCmd)
+}
+
+// draftRevisions locates the selected draft and lists its revisions.
+func draftRevisions() (string, []revisions.Revision, error) {
+	branch, err := utils.GetCurrentBranch()
+	if err != nil {
+		return "", nil, fmt.Errorf("could not determine branch: %w", err)
+	}
+	branch = strings.TrimSpace(branch)
+
+	var dir string
+	if draftBranchLevel {
+		if dir, err = branchArtifactDir(branch); err != nil {
+			return "", nil, err
+		}
+	} else {
+		hash, err := utils.ExecGit("rev-parse", "--verify", draftCommit+"^{commit}")
+		if err != nil {
+			return "", nil, fmt.Errorf("unknown commit %q", draftCommit)
+		}
+		var ok bool
+		if dir, ok = findCommitDraftDir(branch, hash); !ok {
+			return "", nil, fmt.Errorf("no draft saved for commit %s", hash[:7])
+		}
+	}
+
+	// Drafts saved before revisions existed become revision 1
+	if err := snapshotUnversionedDraft(dir); err != nil {
+		return "", nil, err
+	}
+	revs, err := revisions.List(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(revs) == 0 {
+		return "", nil, fmt.Errorf("no draft saved in %s", dir)
+	}
+	return dir, revs, nil
+}
+
+// revisionArg parses a revision number that must exist in revs.
+func revisionArg(arg string, revs []revisions.Revision) (int, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid revision %q", arg)
+	}
+	for _, rev := range revs {
+		if rev.Number == n {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("revision %d does not exist (have 1-%d)", n, revs[len(revs)-1].Number)
+}
+
+// describeRevision summarises how a revision was generated, from its draft_meta.json.
+func describeRevision(path string) string {
+	meta, err := readDraftMeta(path)
+	if err != nil || meta.DraftResult == nil {
+		return "(no metadata)"
+	}
+	parts := []string{meta.GeneratedAt}
+	if meta.Model != "" {
+		parts = append(parts, meta.Model)
+	}
+	if meta.PromptTemplate != "" {
+		parts = append(parts, fmt.Sprintf("%s@%s", meta.PromptTemplate, meta.PromptHash))
+	}
+	parts = append(parts, meta.Mode, fmt.Sprintf("diff ~%d/%d tokens, draft ~%d",
+		meta.DiffTokens, meta.BudgetTokens, meta.DraftTokens))
+	return strings.Join(parts, "  ")
+}
+
+// diffFiles returns the unified diff of two files relative to dir, which keeps the
+// diff headers short; empty when they are identical.
+func diffFiles(dir, a, b string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", "diff", "--no-index", "--no-color", "--", a, b)
+	cmd.Dir = dir
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	// git diff --no-index exits 1 when the files differ
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return "", fmt.Errorf("git diff failed: %w (stderr: %q)", err, stderr.String())
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,159 @@
+// cmd/draft_refine.go
+//
+// Interactive refinement of a saved draft before it becomes a PR.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+var draftRefineCmd = &cobra.Command{
+	Use:   "refine",
+	Short: "Revise the draft in a chat with the LLM, then save it as a new revision",
+	Long: `Opens a conversation seeded with the saved draft, its commit message(s) and diff. Describe the
+changes you want ("shorter", "add a testing section"); each answer is shown as a diff against the
+previous version. 'accept' saves the result as a new revision that 'pr create' will use,
+'quit' discards it.`,
+	Args: cobra.NoArgs,
+	Run:  runDraftRefine,
+}
+
+func init() {
+	draftCmd.AddCommand(draftRefineCmd)
+}
+
+func runDraftRefine(cmd *cobra.Command, args []string) {
+	dir, _, err := draftRevisions()
+	if err != nil {
+		fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+		return
+	}
+	meta, err := readDraftMeta(dir)
+	if err != nil || meta.DraftResult == nil {
+		fmt.Printf("[PRBuddy-Go] Error: draft metadata is missing in %s\n", dir)
+		return
+	}
+	original, err := os.ReadFile(filepath.Join(dir, "draft.md"))
+	if err != nil {
+		fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+		return
+	}
+
+	// The same commit message(s) and diff the draft was generated from
+	var input llm.DraftInput
+	if meta.Scope == draftScopeBranch {
+		input, err = llm.BranchDraftInput(meta.Base)
+	} else {
+		input, err = llm.CommitDraftInput(meta.Head)
+	}
+	if err != nil {
+		fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+		return
+	}
+
+	conversationID, err := llm.StartPRConversationFromDraft(context.Background(), input.CommitMessage, input.Diff, string(original))
+	if err != nil {
+		fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+		return
+	}
+	defer contextpkg.ConversationManagerInstance.RemoveConversation(conversationID)
+
+	color.Cyan("\n[PRBuddy-Go] Refining %s", filepath.Join(dir, "draft.md"))
+	color.Yellow("Describe a change, or: 'show' the draft, 'diff' against the saved draft, 'accept', 'quit'.\n")
+
+	current := strings.TrimSpace(string(original))
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		color.Green("You:")
+		fmt.Print("> ")
+		line, err := reader.ReadString('\n')
+		request := strings.TrimSpace(line)
+		if err != nil && request == "" {
+			fmt.Println("\n[PRBuddy-Go] Refinement cancelled; the saved draft is unchanged.")
+			return
+		}
+
+		switch strings.ToLower(request) {
+		case "":
+			continue
+		case "quit", "q", "exit":
+			fmt.Println("[PRBuddy-Go] Refinement discarded; the saved draft is unchanged.")
+			return
+		case "show":
+			fmt.Println(current)
+			continue
+		case "diff":
+			printDraftDiff(strings.TrimSpace(string(original)), current)
+			continue
+		case "accept", "a":
+			if current == strings.TrimSpace(string(original)) {
+				fmt.Println("[PRBuddy-Go] No changes to save.")
+				return
+			}
+			if err := saveDraftRevision(meta, current, llm.DraftModeRefined); err != nil {
+				fmt.Printf("[PRBuddy-Go] Error: could not save the refined draft: %v\n", err)
+				continue
+			}
+			fmt.Printf("[PRBuddy-Go] Refined draft saved to: %s\n", filepath.Join(dir, "draft.md"))
+			return
+		}
+
+		fmt.Println("[PRBuddy-Go] Revising...")
+		revised, err := llm.ContinuePRConversation(context.Background(), conversationID, request)
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			continue
+		}
+		printDraftDiff(current, revised)
+		current = revised
+	}
+}
+
+// printDraftDiff shows what changed between two versions of a draft.
+func printDraftDiff(before, after string) {
+	tmp, err := os.MkdirTemp("", "prbuddy-refine-")
+	if err != nil {
+		fmt.Println(after)
+		return
+	}
+	defer os.RemoveAll(tmp)
+
+	for name, content := range map[string]string{"previous": before, "revised": after} {
+		_ = os.MkdirAll(filepath.Join(tmp, name), 0755)
+		_ = os.WriteFile(filepath.Join(tmp, name, "draft.md"), []byte(content+"\n"), 0644)
+	}
+	out, err := diffFiles(tmp, filepath.Join("previous", "draft.md"), filepath.Join("revised", "draft.md"))
+	if err != nil {
+		fmt.Println(after)
+		return
+	}
+	if out == "" {
+		fmt.Println("[PRBuddy-Go] No changes.")
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "):
+			continue
+		case strings.HasPrefix(line, "+"):
+			fmt.Println(green(line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Println(red(line))
+		case strings.HasPrefix(line, "@@"):
+			fmt.Println(cyan(line))
+		default:
+			fmt.Println(line)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// cmd/generate.go
+//
+// On-demand generation of PR drafts. Unlike the post-commit hook this always
+// regenerates, so it can be used to refresh a draft after amending or rebasing;
+// earlier generations stay available as revisions (see 'draft history').
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate PRBuddy-Go artifacts on demand",
+}
+
+var generatePRCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Generate (or regenerate) the PR draft for HEAD or the whole branch",
+	Long: `Generates a PR draft and stores it in .git/pr_buddy_db.
+By default the draft covers the latest commit. With --branch it covers every commit
+since the branch diverged from its base, using the cumulative merge-base...HEAD diff;
+'pr create' prefers such a branch-level draft when one exists.`,
+	Run: runGeneratePR,
+}
+
+var (
+	generateWholeBranch bool
+	generateBase        string
+)
+
+func init() {
+	generatePRCmd.Flags().BoolVar(&generateWholeBranch, "branch", false, "Draft from every commit since the base branch")
+	generatePRCmd.Flags().StringVar(&generateBase, "base", "", "Base branch for --branch drafts (default: detected)")
+	generateCmd.AddCommand(generatePRCmd)
+	rootCmd.AddCommand(generateCmd)
+}
+
+func runGeneratePR(cmd *cobra.Command, args []string) {
+	branchName, err := utils.GetCurrentBranch()
+	if err != nil || branchName == "HEAD" || branchName == "" {
+		fmt.Println("[PRBuddy-Go] Error: detached HEAD or unknown branch")
+		return
+	}
+	branchName = strings.TrimSpace(branchName)
+
+	commitHash, err := utils.GetLatestCommit()
+	if err != nil || commitHash == "" {
+		fmt.Println("[PRBuddy-Go] Error: could not determine commit hash")
+		return
+	}
+	commitHash = strings.TrimSpace(commitHash)
+
+	if generateWholeBranch {
+		fmt.Println("[PRBuddy-Go] Generating branch-level PR draft...")
+	} else {
+		fmt.Printf("[PRBuddy-Go] Generating PR draft for commit %s...\n", commitHash[:7])
+	}
+
+	input, result, err := generateDraftPR(generateWholeBranch, generateBase)
+	if err != nil {
+		handleGenerationError(err)
+		return
+	}
+
+	logDir, err := saveArtifacts(branchName, commitHash, input, result)
+	if err != nil {
+		fmt.Printf("[PRBuddy-Go] Error: could not save artifacts: %v\n", err)
+		return
+	}
+
+	if input.Base != "" {
+		fmt.Printf("[PRBuddy-Go] Branch draft covers %d commit(s) since %s\n", input.Commits, input.Base)
+	}
+	if result.Mode == llm.DraftModeMapReduce {
+		fmt.Printf("[PRBuddy-Go] Large diff: drafted from %d file summaries\n", len(result.FileSummaries))
+	}
+	fmt.Printf("[PRBuddy-Go] Draft saved to: %s\n", filepath.Join(logDir, "draft.md"))
+}
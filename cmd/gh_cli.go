@@ -0,0 +1,182 @@
+// cmd/gh_cli.go
+//
+// The gh CLI as a forge, used for GitHub when no API token is available.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/forge"
+)
+
+// ghCLI implements forge.Forge with gh, which brings its own authentication.
+type ghCLI struct{}
+
+var _ forge.Forge = ghCLI{}
+
+func (ghCLI) Name() string { return "GitHub (gh CLI)" }
+
+func (ghCLI) DefaultBranch(ctx context.Context) (string, error) {
+	out, err := runGH(5*time.Second, "repo", "view", "--json", "defaultBranchRef", "--jq", ".defaultBranchRef.name")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func (ghCLI) Labels(ctx context.Context) ([]string, error) {
+	out, err := runGH(10*time.Second, "label", "list", "--limit", "500", "--json", "name", "--jq", ".[].name")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// CreatePullRequest runs 'gh pr create', which sets assignees, reviewers and labels itself.
+func (ghCLI) CreatePullRequest(ctx context.Context, opts forge.PullRequestOptions) (*forge.PullRequest, error) {
+	bodyFile, cleanup, err := ghBodyFile(opts.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	args := []string{"pr", "create", "--title", opts.Title, "--body-file", bodyFile, "--head", opts.Head, "--base", opts.Base}
+	if len(opts.Assignees) > 0 {
+		args = append(args, "--assignee", strings.Join(opts.Assignees, ","))
+	}
+	if len(opts.Reviewers) > 0 {
+		args = append(args, "--reviewer", strings.Join(opts.Reviewers, ","))
+	}
+	if len(opts.Labels) > 0 {
+		args = append(args, "--label", strings.Join(opts.Labels, ","))
+	}
+	if opts.Draft {
+		args = append(args, "--draft")
+	}
+
+	// Execute with timeout and sanitized environment
+	out, err := runGH(30*time.Second, args...)
+	if err != nil {
+		return nil, fmt.Errorf("gh command failed: %w", err)
+	}
+	url := extractPRURL(out)
+	return &forge.PullRequest{Number: prNumberFromURL(url), URL: url}, nil
+}
+
+func (ghCLI) FindPullRequest(ctx context.Context, head string) (*forge.PullRequest, error) {
+	out, err := runGH(10*time.Second, "pr", "list", "--head", head, "--state", "open", "--limit", "1", "--json", "number,url,title,body")
+	if err != nil {
+		return nil, err
+	}
+	var prs []struct {
+		Number int    `json:"number"`
+		URL    string `json:"url"`
+		Title  string `json:"title"`
+		Body   string `json:"body"`
+	}
+	if err := json.Unmarshal([]byte(out), &prs); err != nil {
+		return nil, fmt.Errorf("unexpected gh output: %w", err)
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	pr := prs[0]
+	return &forge.PullRequest{Number: pr.Number, URL: pr.URL, Title: pr.Title, Body: pr.Body}, nil
+}
+
+func (ghCLI) UpdatePullRequest(ctx context.Context, number int, title, body string) error {
+	bodyFile, cleanup, err := ghBodyFile(body)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if _, err := runGH(30*time.Second, "pr", "edit", strconv.Itoa(number), "--title", title, "--body-file", bodyFile); err != nil {
+		return fmt.Errorf("gh command failed: %w", err)
+	}
+	return nil
+}
+
+// ghBodyFile writes a PR body to a temporary file for gh's --body-file.
+func ghBodyFile(body string) (string, func(), error) {
+	f, err := os.CreateTemp("", "prbuddy-pr-body-*.md")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.Remove(f.Name()) }
+	if _, err := f.WriteString(body); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, err
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return f.Name(), cleanup, nil
+}
+
+// prNumberFromURL returns the number at the end of a PR URL, or 0.
+func prNumberFromURL(url string) int {
+	n, _ := strconv.Atoi(url[strings.LastIndex(url, "/")+1:])
+	return n
+}
+
+func extractPRURL(output string) string {
+	lines := strings.Split(output, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "https://") {
+			return line
+		}
+	}
+	return ""
+}
+
+// runGH executes gh with a timeout and with a sanitized environment
+func runGH(timeout time.Duration, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "gh", args...)
+	cmd.Env = sanitizeEnvForGH(os.Environ())
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("gh timed out running: gh %s", strings.Join(args, " "))
+	}
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("%s", msg)
+	}
+	return stdout.String(), nil
+}
+
+func sanitizeEnvForGH(env []string) []string {
+	out := make([]string, 0, len(env))
+	for _, kv := range env {
+		// These can override gh's stored auth and cause mysterious 401s; gh is only
+		// used when no token was found, so they can't be the intended credentials
+		if strings.HasPrefix(kv, "GITHUB_TOKEN=") || strings.HasPrefix(kv, "GH_TOKEN=") {
+			continue
+		}
+		out = append(out, kv)
+	}
+	return out
+}
@@ -15,8 +15,9 @@ import (
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize PRBuddy-Go in the current Git repository.",
-	Long: `Installs a post-commit hook (optionally) and creates the .git/pr_buddy_db directory.
-If you choose not to install the post-commit hook now, you can install it later manually.`,
+	Long: `Installs a post-commit hook and a prepare-commit-msg hook (both optional) and creates
+the .git/pr_buddy_db directory. If you choose not to install the hooks now, you can install
+them later manually.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("[PRBuddy-Go] Initializing PRBuddy-Go...")
 
@@ -43,7 +44,23 @@ If you choose not to install the post-commit hook now, you can install it later
 			fmt.Println("[PRBuddy-Go] Skipping post-commit hook installation.")
 		}
 
-		// 2. Create .git/pr_buddy_db directory
+		// 2. Offer commit message suggestions via prepare-commit-msg
+		fmt.Print("[PRBuddy-Go] Suggest commit messages from staged changes?  [y/N] ")
+		userInput, err = reader.ReadString('\n')
+		if err != nil {
+			userInput = "n"
+		}
+		userInput = strings.TrimSpace(strings.ToLower(userInput))
+
+		if userInput == "y" || userInput == "yes" {
+			if err := hooks.InstallPrepareCommitMsgHook(); err != nil {
+				fmt.Printf("[PRBuddy-Go] Error installing prepare-commit-msg hook: %v\n", err)
+			}
+		} else {
+			fmt.Println("[PRBuddy-Go] Skipping prepare-commit-msg hook installation.")
+		}
+
+		// 3. Create .git/pr_buddy_db directory
 		repoPath, err := utils.GetRepoPath()
 		if err != nil {
 			fmt.Printf("[PRBuddy-Go] Error retrieving repository path: %v\n", err)
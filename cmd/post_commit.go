@@ -18,6 +18,7 @@ import (
 
 	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
 	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/revisions"
 	"github.com/soyuz43/prbuddy-go/internal/utils"
 	"github.com/spf13/cobra"
 )
@@ -25,6 +26,9 @@ import (
 var (
 	extensionActive bool
 	nonInteractive  bool
+	branchDraft     bool
+	draftBase       string
+	forceDraft      bool
 )
 
 // ConversationLog represents the structure for logging conversations
@@ -38,7 +42,8 @@ var postCommitCmd = &cobra.Command{
 	Use:   "post-commit",
 	Short: "Generate PR draft artifacts (idempotent)",
 	Long: `Generates PR draft artifacts and stores them in .git/pr_buddy_db.
-This hook is safe to run repeatedly and will exit immediately if artifacts already exist.
+This hook is safe to run repeatedly and will exit immediately if artifacts already exist,
+unless --force is given, in which case the draft is regenerated as a new revision.
 Does NOT create PRs or prompt for user input.`,
 	Run: runPostCommit,
 }
@@ -46,6 +51,9 @@ Does NOT create PRs or prompt for user input.`,
 func init() {
 	postCommitCmd.Flags().BoolVar(&extensionActive, "extension-active", false, "Indicates extension connectivity")
 	postCommitCmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "Disable interactive prompts")
+	postCommitCmd.Flags().BoolVar(&branchDraft, "branch", false, "Draft from every commit since the base branch instead of only HEAD")
+	postCommitCmd.Flags().StringVar(&draftBase, "base", "", "Base branch for --branch drafts (default: detected)")
+	postCommitCmd.Flags().BoolVar(&forceDraft, "force", false, "Regenerate an existing draft as a new revision")
 	rootCmd.AddCommand(postCommitCmd)
 }
 
@@ -69,8 +77,14 @@ func runPostCommit(cmd *cobra.Command, args []string) {
 	}
 	commitHash = strings.TrimSpace(commitHash)
 
-	// 2. PRIMARY GATE: Check if draft already exists
-	if draftAlreadyExists(branchName, commitHash) {
+	// 2. PRIMARY GATE: Check if draft already exists (--force adds a new revision instead)
+	if !forceDraft && branchDraft && branchDraftIsCurrent(branchName, commitHash) {
+		if !nonInteractive {
+			fmt.Printf("[PRBuddy-Go] Skipping: branch draft already covers commit %s\n", commitHash[:7])
+		}
+		return
+	}
+	if !forceDraft && !branchDraft && draftAlreadyExists(branchName, commitHash) {
 		if !nonInteractive {
 			fmt.Printf("[PRBuddy-Go] Skipping: draft already exists for commit %s\n", commitHash[:7])
 		}
@@ -78,14 +92,15 @@ func runPostCommit(cmd *cobra.Command, args []string) {
 	}
 
 	// 3. Generate draft (only if needed)
-	draftPR, err := generateDraftPR()
+	input, result, err := generateDraftPR(branchDraft, draftBase)
 	if err != nil {
 		handleGenerationError(err)
 		return
 	}
+	draftPR := result.Draft
 
 	// 4. Save artifacts (core responsibility of hook)
-	logDir, err := saveArtifacts(branchName, commitHash, draftPR)
+	logDir, err := saveArtifacts(branchName, commitHash, input, result)
 	if err != nil {
 		fmt.Printf("[PRBuddy-Go] Warning: could not save artifacts: %v\n", err)
 		return
@@ -101,69 +116,102 @@ func runPostCommit(cmd *cobra.Command, args []string) {
 	} else if !nonInteractive {
 		fmt.Printf("[PRBuddy-Go] Draft saved to: %s\n",
 			filepath.Join(logDir, "draft.md"))
+		if result.Mode == llm.DraftModeMapReduce {
+			fmt.Printf("[PRBuddy-Go] Large diff: drafted from %d file summaries (see draft_meta.json)\n",
+				len(result.FileSummaries))
+		}
+		if input.Base != "" {
+			fmt.Printf("[PRBuddy-Go] Branch draft covers %d commit(s) since %s\n", input.Commits, input.Base)
+		}
 		fmt.Println("[PRBuddy-Go] Run 'prbuddy-go pr create' to create the GitHub PR")
 	}
 }
 
 // PRIMARY GATE: Check if we've already processed this commit
 func draftAlreadyExists(branch, headHash string) bool {
-	repoPath, err := utils.GetRepoPath()
-	if err != nil || len(headHash) < 7 {
+	logDir, err := commitArtifactDir(branch, headHash)
+	if err != nil {
 		return false
 	}
 
-	logDir := filepath.Join(
-		repoPath,
-		".git", "pr_buddy_db",
-		utils.SanitizeBranchName(branch),
-		fmt.Sprintf("commit-%s", headHash[:7]),
-	)
-
 	// Check for ANY artifact - indicates we've processed this commit
 	return utils.FileExists(filepath.Join(logDir, "draft.md")) ||
 		utils.FileExists(filepath.Join(logDir, "conversation.json"))
 }
 
-// Generate draft PR
-func generateDraftPR() (string, error) {
-	// Get commit message and diffs
-	commitMessage, diffs, err := llm.GeneratePreDraftPR()
+// Generate draft PR, for HEAD only or (wholeBranch) for every commit since the base branch
+func generateDraftPR(wholeBranch bool, base string) (llm.DraftInput, *llm.DraftResult, error) {
+	// Get commit message(s) and the full diff; GenerateDraft decides how to fit it
+	var input llm.DraftInput
+	var err error
+	if wholeBranch {
+		if base == "" {
+			if base, err = detectBaseBranch(); err != nil {
+				return input, nil, fmt.Errorf("base branch detection failed (use --base): %w", err)
+			}
+			// A stacked branch is described by its own commits only
+			if parent := recordedStackParent(base); parent != "" {
+				fmt.Printf("[PRBuddy-Go] Stacked on '%s'; drafting the commits since it (use --base to change)\n", parent)
+				base = parent
+			}
+		}
+		input, err = llm.BranchDraftInput(base)
+	} else {
+		input, err = llm.LatestCommitDraftInput()
+	}
 	if err != nil {
-		return "", fmt.Errorf("pre-draft generation failed: %w", err)
+		return input, nil, fmt.Errorf("pre-draft generation failed: %w", err)
 	}
 
 	// Skip if no changes
-	if strings.TrimSpace(diffs) == "" {
-		return "", fmt.Errorf("no detectable changes")
+	if strings.TrimSpace(input.Diff) == "" {
+		return input, nil, fmt.Errorf("no detectable changes")
 	}
 
-	// Generate draft
-	draftPR, err := llm.GenerateDraftPR(commitMessage, diffs)
+	// Generate draft (map-reduce over files when the diff exceeds the model window)
+	result, err := llm.GenerateDraft(context.Background(), input)
 	if err != nil {
-		return "", fmt.Errorf("draft generation failed: %w", err)
+		return input, nil, fmt.Errorf("draft generation failed: %w", err)
 	}
 
-	return strings.TrimSpace(utils.StripOuterMarkdownCodeFence(draftPR)), nil
+	result.Draft = strings.TrimSpace(utils.StripOuterMarkdownCodeFence(result.Draft))
+	return input, result, nil
 }
 
-// Save all artifacts in one place
-func saveArtifacts(branch, hash, draft string) (string, error) {
-	repoPath, err := utils.GetRepoPath()
-	if err != nil {
-		return "", fmt.Errorf("repo path detection: %w", err)
+// Save all artifacts in one place: the commit directory, or the branch directory
+// for branch-level drafts
+func saveArtifacts(branch, hash string, input llm.DraftInput, result *llm.DraftResult) (string, error) {
+	draft := result.Draft
+	meta := draftMeta{
+		Scope:       draftScopeCommit,
+		Branch:      branch,
+		Head:        hash,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		DraftResult: result,
 	}
 
-	logDir := filepath.Join(
-		repoPath,
-		".git", "pr_buddy_db",
-		utils.SanitizeBranchName(branch),
-		fmt.Sprintf("commit-%s", hash[:7]),
-	)
+	logDir, err := commitArtifactDir(branch, hash)
+	if input.Base != "" {
+		meta.Scope = draftScopeBranch
+		meta.Base, meta.MergeBase, meta.Commits = input.Base, input.MergeBase, input.Commits
+		logDir, err = branchArtifactDir(branch)
+	}
+	if err != nil {
+		return "", err
+	}
 
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return "", fmt.Errorf("log directory creation: %w", err)
 	}
 
+	// Every generation becomes a new revision; the top-level files are the current one
+	if err := snapshotUnversionedDraft(logDir); err != nil {
+		return logDir, fmt.Errorf("keeping the previous draft: %w", err)
+	}
+	if meta.Revision, err = revisions.Next(logDir); err != nil {
+		return logDir, err
+	}
+
 	// Save draft
 	if err := utils.WriteFile(
 		filepath.Join(logDir, "draft.md"),
@@ -193,6 +241,38 @@ func saveArtifacts(branch, hash, draft string) (string, error) {
 		return logDir, err
 	}
 
+	// Save the structured draft the markdown was rendered from, when the model produced one
+	if result.Structured != nil {
+		draftJSON, err := utils.MarshalJSON(result.Structured)
+		if err != nil {
+			return logDir, err
+		}
+		if err := utils.WriteFile(
+			filepath.Join(logDir, "draft.json"),
+			[]byte(draftJSON),
+		); err != nil {
+			return logDir, err
+		}
+	} else {
+		// Don't leave a structured draft from an earlier generation behind
+		_ = os.Remove(filepath.Join(logDir, "draft.json"))
+	}
+
+	// Save how the draft was produced (and the per-file summaries in map-reduce mode)
+	metaJSON, err := utils.MarshalJSON(meta)
+	if err != nil {
+		return logDir, err
+	}
+	if err := utils.WriteFile(
+		filepath.Join(logDir, "draft_meta.json"),
+		[]byte(metaJSON),
+	); err != nil {
+		return logDir, err
+	}
+
+	if err := revisions.Save(logDir, meta.Revision, draftFiles); err != nil {
+		return logDir, fmt.Errorf("saving revision: %w", err)
+	}
 	return logDir, nil
 }
 
@@ -205,8 +285,12 @@ func communicateWithExtension(branch, hash, draft string) error {
 	if err != nil {
 		return fmt.Errorf("port retrieval: %w", err)
 	}
+	token, err := utils.ReadTokenFile()
+	if err != nil {
+		return fmt.Errorf("token retrieval: %w", err)
+	}
 
-	return retryCommunication(port, branch, hash, draft)
+	return retryCommunication(port, token, branch, hash, draft)
 }
 
 func activateExtension() error {
@@ -219,7 +303,7 @@ func activateExtension() error {
 	return nil
 }
 
-func retryCommunication(port int, branch, hash, draft string) error {
+func retryCommunication(port int, token, branch, hash, draft string) error {
 	client := http.Client{Timeout: 2 * time.Second}
 
 	payload := map[string]interface{}{
@@ -235,14 +319,23 @@ func retryCommunication(port int, branch, hash, draft string) error {
 	}
 
 	for i := 0; i < 3; i++ {
-		resp, err := client.Post(
+		req, err := http.NewRequest(
+			http.MethodPost,
 			fmt.Sprintf("http://localhost:%d/extension", port),
-			"application/json",
 			strings.NewReader(string(jsonPayload)),
 		)
-
-		if err == nil && resp != nil && resp.StatusCode == http.StatusOK {
-			return nil
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
 		}
 
 		time.Sleep(500 * time.Millisecond)
@@ -1,96 +1,166 @@
 // cmd/pr_create.go
 //
-// Command to create a GitHub PR from saved draft artifacts.
+// Command to create a GitHub PR (or GitLab merge request) from saved draft artifacts.
 // This command:
-// 1. Ensures the branch is pushed to remote
-// 2. Uses saved draft artifacts to create the PR
-// 3. Handles all GitHub-specific logic
+// 1. Finds the saved draft artifacts (and with --edit, lets you review them)
+// 2. Ensures the branch is pushed to remote
+// 3. Uses the draft to create the PR
+// 4. Handles all forge-specific logic, through the GitHub or GitLab REST API when
+//    a token is available and through the gh CLI otherwise
 
 package cmd
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/soyuz43/prbuddy-go/internal/forge"
+	"github.com/soyuz43/prbuddy-go/internal/prdraft"
 	"github.com/soyuz43/prbuddy-go/internal/utils"
 	"github.com/spf13/cobra"
 )
 
 var (
-	baseBranch string
-	assignees  string
-	reviewers  string
-	labels     string
+	baseBranch    string
+	assignees     string
+	reviewers     string
+	labels        string
+	draftPR       bool
+	editDraftFlag bool
+	noStack       bool
 )
 
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Create or update the pull request of the current branch",
+}
+
 var prCreateCmd = &cobra.Command{
-	Use:   "pr create",
-	Short: "Create a GitHub PR from saved draft artifacts",
+	Use:   "create",
+	Short: "Create a GitHub PR or GitLab merge request from saved draft artifacts",
 	Long: `Creates a GitHub PR using the most recently saved draft artifacts.
 This command ensures your branch is pushed to the remote before creating the PR,
-making sure GitHub can properly autofill the PR details.`,
+making sure GitHub can properly autofill the PR details.
+
+The forge is detected from the origin remote. On GitHub the PR is created through the REST API
+with a token from PRBUDDY_GITHUB_TOKEN, GITHUB_TOKEN, GH_TOKEN or git's credential helper, and
+through the gh CLI without one. On GitLab a merge request is created with a token from
+PRBUDDY_GITLAB_TOKEN, GITLAB_TOKEN, GITLAB_PRIVATE_TOKEN or git's credential helper.
+
+With --edit the draft opens in $VISUAL/$EDITOR first. The title is re-read from the first
+heading and the result is checked (title present and short enough, PR template sections,
+no stray code fences) before anything is pushed. Emptying the file aborts, as with git commit.
+
+Without --base, a branch cut from another feature branch that has an open PR is stacked on
+it: the PR targets that branch, and every open PR of the stack gets a "Stack" section
+linking the others, kept current by later 'pr create' and 'pr update' runs. --no-stack
+targets the default branch instead. --draft opens the PR as a draft.
+
+When the branch already has an open PR, you are offered to update it instead (see 'pr update').`,
 	Run: runPRCreate,
 }
 
 func init() {
 	prCreateCmd.Flags().StringVar(&baseBranch, "base", "", "The base branch to create the PR against (default: detected)")
-	prCreateCmd.Flags().StringVar(&assignees, "assignees", "", "Comma-separated list of GitHub users to assign to the PR")
-	prCreateCmd.Flags().StringVar(&reviewers, "reviewers", "", "Comma-separated list of GitHub users to request reviews from")
-	prCreateCmd.Flags().StringVar(&labels, "labels", "", "Comma-separated list of labels to add to the PR")
-	rootCmd.AddCommand(prCreateCmd)
+	prCreateCmd.Flags().StringVar(&assignees, "assignees", "", "Comma-separated list of users to assign to the PR")
+	prCreateCmd.Flags().StringVar(&reviewers, "reviewers", "", "Comma-separated list of users to request reviews from")
+	prCreateCmd.Flags().StringVar(&labels, "labels", "", "Comma-separated list of labels to add to the PR (default: suggested from commit types)")
+	prCreateCmd.Flags().BoolVar(&draftPR, "draft", false, "Open the PR as a draft (GitLab: a Draft: merge request)")
+	prCreateCmd.Flags().BoolVar(&editDraftFlag, "edit", false, "Review and edit the draft in your editor before creating the PR")
+	prCreateCmd.Flags().BoolVar(&noStack, "no-stack", false, "Target the default branch even when the branch was cut from another branch with an open PR")
+	prCmd.AddCommand(prCreateCmd)
+	rootCmd.AddCommand(prCmd)
 }
 
 func runPRCreate(cmd *cobra.Command, args []string) {
 	fmt.Println("[PRBuddy-Go] Starting PR creation workflow...")
 
 	// 1. Get current branch and commit
-	branchName, err := utils.ExecGit("rev-parse", "--abbrev-ref", "HEAD")
-	if err != nil || branchName == "HEAD" || branchName == "" {
-		fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
-		fmt.Println("[PRBuddy-Go] Failed to determine current branch. Are you in detached HEAD state?")
+	branchName, commitHash, ok := currentBranchAndHead()
+	if !ok {
 		return
 	}
-	branchName = strings.TrimSpace(branchName)
 
-	commitHash, err := utils.ExecGit("rev-parse", "HEAD")
-	if err != nil {
+	// An open PR for the branch is updated rather than duplicated
+	if client, pr := openPullRequest(branchName); pr != nil {
+		fmt.Printf("[PRBuddy-Go] Branch '%s' already has an open PR: %s\n", branchName, pr.URL)
+		if !askYesNo("[PRBuddy-Go] Update it instead?  [Y/n] ", true) {
+			fmt.Println("[PRBuddy-Go] Nothing created. Run 'prbuddy-go pr update' to refresh it later.")
+			return
+		}
+		if err := updatePR(client, pr, branchName, commitHash); err != nil {
+			fmt.Printf("[PRBuddy-Go] PR update failed: %v\n", err)
+		}
+		return
+	}
+
+	// 2. Find saved draft
+	draftPath, err := findDraftArtifacts(branchName, commitHash)
+	if errors.Is(err, errStaleBranchDraft) {
 		fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
-		fmt.Println("[PRBuddy-Go] Failed to determine current commit hash.")
+		fmt.Println("[PRBuddy-Go] Run 'prbuddy-go generate pr --branch' to refresh it first.")
+		return
+	} else if err != nil {
+		fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+		fmt.Println("[PRBuddy-Go] No draft found for current commit. Run 'prbuddy-go post-commit' first.")
 		return
 	}
-	commitHash = strings.TrimSpace(commitHash)
 
-	// 2. Ensure branch is pushed to remote
+	// 3. Let the user review the draft before anything leaves the machine
+	var title string
+	if editDraftFlag {
+		if draftPath, title, err = editDraft(draftPath); errors.Is(err, errEmptyDraft) {
+			fmt.Println("[PRBuddy-Go] Aborting PR creation due to empty draft.")
+			return
+		} else if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+	}
+
+	// 4. Ensure branch is pushed to remote
 	if err := pushBranch(branchName); err != nil {
 		fmt.Printf("[PRBuddy-Go] Error pushing branch: %v\n", err)
 		fmt.Println("[PRBuddy-Go] PR creation requires your branch to be pushed to the remote.")
 		return
 	}
 
-	// 3. Find saved draft
-	draftPath, err := findDraftArtifacts(branchName, commitHash)
-	if err != nil {
-		fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
-		fmt.Println("[PRBuddy-Go] No draft found for current commit. Run 'prbuddy-go post-commit' first.")
-		return
-	}
-
-	// 4. Create PR using saved draft
-	if err := createPRFromDraft(branchName, draftPath); err != nil {
+	// 5. Create PR using saved draft
+	if err := createPRFromDraft(branchName, commitHash, draftPath, title); err != nil {
 		fmt.Printf("[PRBuddy-Go] PR creation failed: %v\n", err)
-		fmt.Println("[PRBuddy-Go] Tip: check `gh auth status` and ensure your repo remote points to GitHub.")
+		fmt.Println("[PRBuddy-Go] Tip: set GITHUB_TOKEN or GITLAB_TOKEN (or check `gh auth status`) and ensure origin points to GitHub or GitLab.")
 		return
 	}
 
 	fmt.Println("[PRBuddy-Go] PR creation workflow completed successfully!")
 }
 
+// currentBranchAndHead returns the checked-out branch and its commit, reporting
+// why when there is none.
+func currentBranchAndHead() (string, string, bool) {
+	branchName, err := utils.ExecGit("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil || branchName == "HEAD" || branchName == "" {
+		fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+		fmt.Println("[PRBuddy-Go] Failed to determine current branch. Are you in detached HEAD state?")
+		return "", "", false
+	}
+
+	commitHash, err := utils.ExecGit("rev-parse", "HEAD")
+	if err != nil {
+		fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+		fmt.Println("[PRBuddy-Go] Failed to determine current commit hash.")
+		return "", "", false
+	}
+	return strings.TrimSpace(branchName), strings.TrimSpace(commitHash), true
+}
+
 func pushBranch(branchName string) error {
 	fmt.Printf("[PRBuddy-Go] Ensuring branch '%s' is pushed to remote...\n", branchName)
 
@@ -118,94 +188,136 @@ func pushBranch(branchName string) error {
 	return nil
 }
 
+// errStaleBranchDraft describes a branch-level draft generated before HEAD,
+// which would describe commits that are missing or have since changed.
+var errStaleBranchDraft = errors.New("the branch draft was generated for an older HEAD")
+
+// findDraftArtifacts prefers a branch-level draft (see 'generate pr --branch') and
+// falls back to the draft for the current commit. A stale branch draft is skipped
+// with a warning; errStaleBranchDraft is returned when no commit draft replaces it.
 func findDraftArtifacts(branch, commit string) (string, error) {
-	repoPath, err := utils.GetRepoPath()
-	if err != nil {
-		return "", fmt.Errorf("repo path detection: %w", err)
+	staleBranchDraft := false
+	if branchDir, err := branchArtifactDir(branch); err == nil {
+		branchDraft := filepath.Join(branchDir, "draft.md")
+		if utils.FileExists(branchDraft) {
+			if branchDraftIsCurrent(branch, commit) {
+				fmt.Println("[PRBuddy-Go] Using branch-level draft")
+				return branchDraft, nil
+			}
+			staleBranchDraft = true
+		}
 	}
 
-	logDir := filepath.Join(
-		repoPath,
-		".git", "pr_buddy_db",
-		utils.SanitizeBranchName(branch),
-		fmt.Sprintf("commit-%s", commit[:7]),
-	)
+	logDir, err := commitArtifactDir(branch, commit)
+	if err != nil {
+		return "", err
+	}
 
 	draftPath := filepath.Join(logDir, "draft.md")
 	if _, err := os.Stat(draftPath); os.IsNotExist(err) {
+		if staleBranchDraft {
+			return "", fmt.Errorf("%w and there is no draft for the current commit", errStaleBranchDraft)
+		}
 		return "", fmt.Errorf("draft not found at %s", draftPath)
 	}
+	if staleBranchDraft {
+		fmt.Printf("[PRBuddy-Go] Warning: %v; using the draft for the current commit instead\n", errStaleBranchDraft)
+	}
 
 	return draftPath, nil
 }
 
-func createPRFromDraft(branch, draftPath string) error {
-	// Extract title from draft
-	title, err := extractPRTitle(draftPath)
+// createPRFromDraft opens the PR with draftPath as its body. An empty title is
+// taken from the draft.
+func createPRFromDraft(branch, head, draftPath, title string) error {
+	if title == "" {
+		var err error
+		if title, err = extractPRTitle(draftPath); err != nil {
+			return fmt.Errorf("title extraction: %w", err)
+		}
+	}
+
+	client, err := forgeAPI()
 	if err != nil {
-		return fmt.Errorf("title extraction: %w", err)
+		return err
 	}
 
-	// Detect base branch if not specified
+	// Detect base branch if not specified: the branch below in a stack, or the default branch
 	targetBase := baseBranch
 	if targetBase == "" {
 		base, err := detectBaseBranch()
 		if err != nil {
 			fmt.Printf("[PRBuddy-Go] Warning: %v\n", err)
 			fmt.Println("[PRBuddy-Go] Using 'main' as default base branch")
-			targetBase = "main"
-		} else {
-			targetBase = base
+			base = "main"
+		}
+		targetBase = base
+		if !noStack {
+			if parent, parentPR := detectStackParent(client, branch, base); parent != "" {
+				fmt.Printf("[PRBuddy-Go] Stacking on '%s' (%s); use --no-stack to target %s\n", parent, parentPR.URL, base)
+				targetBase = parent
+			}
 		}
 	}
+	fmt.Printf("[PRBuddy-Go] Creating PR from %s to %s on %s...\n", branch, targetBase, client.Name())
 
-	fmt.Printf("[PRBuddy-Go] Creating PR from %s to %s...\n", branch, targetBase)
-
-	// Build gh pr create command
-	args := []string{"pr", "create", "--title", title, "--body-file", draftPath, "--head", branch, "--base", targetBase}
-
-	if assignees != "" {
-		args = append(args, "--assignees", assignees)
-	}
-	if reviewers != "" {
-		args = append(args, "--reviewers", reviewers)
+	body, err := os.ReadFile(draftPath)
+	if err != nil {
+		return err
 	}
-	if labels != "" {
-		args = append(args, "--labels", labels)
+	prLabels := forge.SplitList(labels)
+	if len(prLabels) == 0 {
+		prLabels = suggestedLabels(filepath.Dir(draftPath))
+		if len(prLabels) > 0 {
+			fmt.Printf("[PRBuddy-Go] Adding suggested labels: %s\n", strings.Join(prLabels, ","))
+		}
 	}
 
-	// Execute with timeout and sanitized environment
-	out, err := runGH(30*time.Second, args...)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	pr, err := client.CreatePullRequest(ctx, forge.PullRequestOptions{
+		Title:     title,
+		Body:      string(body),
+		Head:      branch,
+		Base:      targetBase,
+		Draft:     draftPR,
+		Assignees: forge.SplitList(assignees),
+		Reviewers: forge.SplitList(reviewers),
+		Labels:    prLabels,
+	})
+	if pr == nil {
+		return err
+	}
 	if err != nil {
-		return fmt.Errorf("gh command failed: %w", err)
+		// The PR exists; only assignees, reviewers or labels are missing
+		fmt.Printf("[PRBuddy-Go] Warning: %v\n", err)
 	}
-
-	// Extract and print PR URL
-	url := extractPRURL(out)
-	if url != "" {
-		fmt.Printf("[PRBuddy-Go] PR created: %s\n", url)
+	if pr.URL != "" {
+		fmt.Printf("[PRBuddy-Go] PR created: %s\n", pr.URL)
 	} else {
 		fmt.Println("[PRBuddy-Go] PR created (no URL returned)")
 	}
-
+	if err := writePRSync(branch, prSync{Number: pr.Number, URL: pr.URL, Head: head, Base: targetBase}); err != nil {
+		fmt.Printf("[PRBuddy-Go] Warning: could not record the PR: %v\n", err)
+	}
+	syncStack(client, branch)
 	return nil
 }
 
 func extractPRTitle(draftPath string) (string, error) {
+	// A structured draft saved next to the markdown carries the title explicitly
+	if structured, err := readStructuredDraft(filepath.Dir(draftPath)); err == nil && structured.Title != "" {
+		return structured.Title, nil
+	}
+
 	content, err := os.ReadFile(draftPath)
 	if err != nil {
 		return "", err
 	}
 
-	// Simple title extraction - first H1 or H2 line
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "# ") || strings.HasPrefix(line, "## ") {
-			title := strings.TrimPrefix(line, "# ")
-			title = strings.TrimPrefix(title, "## ")
-			return strings.TrimSpace(title), nil
-		}
+	// First H1 or H2 line
+	if title := prdraft.Title(string(content)); title != "" {
+		return title, nil
 	}
 
 	// Fallback to commit subject
@@ -216,6 +328,45 @@ func extractPRTitle(draftPath string) (string, error) {
 	return strings.TrimSpace(commitMsg), nil
 }
 
+// suggestedLabels returns the labels suggested for the draft in dir, from its commit
+// types and the model, limited to labels that already exist in the repository since
+// GitHub refuses to create a PR with an unknown label (and GitLab would create it).
+func suggestedLabels(dir string) []string {
+	var candidates []string
+	if meta, err := readDraftMeta(dir); err == nil && meta.DraftResult != nil {
+		candidates = append(candidates, meta.Labels...)
+	}
+	if structured, err := readStructuredDraft(dir); err == nil {
+		candidates = append(candidates, structured.Labels...)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	names, err := repositoryLabels()
+	if err != nil {
+		fmt.Printf("[PRBuddy-Go] Warning: skipping suggested labels, could not list repository labels: %v\n", err)
+		return nil
+	}
+	existing := make(map[string]string)
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			existing[strings.ToLower(name)] = name
+		}
+	}
+
+	var result []string
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		name, ok := existing[strings.ToLower(strings.TrimSpace(c))]
+		if ok && !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
 func detectBaseBranch() (string, error) {
 	// 1) Try git symbolic ref: refs/remotes/origin/HEAD -> origin/<base>
 	out, err := utils.ExecGit("symbolic-ref", "refs/remotes/origin/HEAD")
@@ -228,10 +379,13 @@ func detectBaseBranch() (string, error) {
 		}
 	}
 
-	// 2) Ask GitHub via gh
-	b, err := ghRepoDefaultBranch()
-	if err == nil && b != "" {
-		return b, nil
+	// 2) Ask the forge, through its API or gh
+	if client, err := forgeAPI(); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if b, err := client.DefaultBranch(ctx); err == nil && b != "" {
+			return b, nil
+		}
 	}
 
 	// 3) fallback heuristics
@@ -245,64 +399,44 @@ func detectBaseBranch() (string, error) {
 	return "", fmt.Errorf("could not detect base branch")
 }
 
-func ghRepoDefaultBranch() (string, error) {
-	out, err := runGH(5*time.Second, "repo", "view", "--json", "defaultBranchRef", "--jq", ".defaultBranchRef.name")
+// repositoryLabels lists the labels defined in the repository on its forge.
+func repositoryLabels() ([]string, error) {
+	client, err := forgeAPI()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return strings.TrimSpace(out), nil
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return client.Labels(ctx)
 }
 
-func branchExists(name string) bool {
-	_, err := utils.ExecGit("show-ref", "--verify", "--quiet", "refs/heads/"+name)
-	return err == nil
-}
+var (
+	forgeOnce   sync.Once
+	forgeClient forge.Forge
+	forgeErr    error
+)
 
-func extractPRURL(output string) string {
-	lines := strings.Split(output, "\n")
-	for i := len(lines) - 1; i >= 0; i-- {
-		line := strings.TrimSpace(lines[i])
-		if strings.HasPrefix(line, "https://") {
-			return line
+// forgeAPI returns the client for the forge hosting origin, looking up the token
+// only once per run. GitHub falls back to the gh CLI when there is no token.
+func forgeAPI() (forge.Forge, error) {
+	forgeOnce.Do(func() {
+		forgeClient, forgeErr = forge.FromOrigin()
+		if forgeErr == nil {
+			return
 		}
-	}
-	return ""
-}
-
-// runGH executes gh with a timeout and with a sanitized environment
-func runGH(timeout time.Duration, args ...string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "gh", args...)
-	cmd.Env = sanitizeEnvForGH(os.Environ())
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	if ctx.Err() == context.DeadlineExceeded {
-		return "", fmt.Errorf("gh timed out running: gh %s", strings.Join(args, " "))
-	}
-	if err != nil {
-		msg := strings.TrimSpace(stderr.String())
-		if msg == "" {
-			msg = err.Error()
+		if remote, err := forge.OriginRemote(); err == nil && forge.IsGitLabHost(remote.Host) {
+			return
 		}
-		return "", fmt.Errorf("%s", msg)
-	}
-	return stdout.String(), nil
+		if _, err := exec.LookPath("gh"); err != nil {
+			forgeErr = fmt.Errorf("%v, and the gh CLI is not installed", forgeErr)
+			return
+		}
+		forgeClient, forgeErr = ghCLI{}, nil
+	})
+	return forgeClient, forgeErr
 }
 
-func sanitizeEnvForGH(env []string) []string {
-	out := make([]string, 0, len(env))
-	for _, kv := range env {
-		// These can override gh's stored auth and cause mysterious 401s
-		if strings.HasPrefix(kv, "GITHUB_TOKEN=") || strings.HasPrefix(kv, "GH_TOKEN=") {
-			continue
-		}
-		out = append(out, kv)
-	}
-	return out
+func branchExists(name string) bool {
+	_, err := utils.ExecGit("show-ref", "--verify", "--quiet", "refs/heads/"+name)
+	return err == nil
 }
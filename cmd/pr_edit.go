@@ -0,0 +1,102 @@
+// cmd/pr_edit.go
+//
+// Review of the draft in the user's editor before 'pr create --edit' submits it,
+// modelled on git's commit message editor.
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/prdraft"
+	"github.com/soyuz43/prbuddy-go/internal/prtemplate"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// editFileName is where the draft is edited, next to draft.md (cf. COMMIT_EDITMSG).
+const editFileName = "PR_EDITMSG.md"
+
+var errEmptyDraft = errors.New("aborting PR creation due to empty draft")
+
+// editDraft opens the draft at draftPath in the editor until it passes validation,
+// and returns the file to submit as the PR body along with its title. An accepted
+// edit is saved as a new revision of the draft.
+func editDraft(draftPath string) (string, string, error) {
+	original, err := os.ReadFile(draftPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	var required []string
+	if tmpl, err := prtemplate.Find(); err != nil {
+		fmt.Printf("[PRBuddy-Go] Warning: PR template lookup failed: %v\n", err)
+	} else if tmpl != nil {
+		required = tmpl.Headings
+	}
+
+	dir := filepath.Dir(draftPath)
+	editPath := filepath.Join(dir, editFileName)
+	content := prdraft.WithInstructions(string(original), nil)
+	reader := bufio.NewReader(os.Stdin)
+
+	var edited string
+	for {
+		if err := utils.WriteFile(editPath, []byte(content)); err != nil {
+			return "", "", err
+		}
+		fmt.Printf("[PRBuddy-Go] Waiting for your editor (%s) to close the draft...\n", utils.Editor())
+		if err := utils.EditFile(editPath); err != nil {
+			return "", "", err
+		}
+		data, err := os.ReadFile(editPath)
+		if err != nil {
+			return "", "", err
+		}
+
+		edited = prdraft.StripInstructions(string(data))
+		if edited == "" {
+			return "", "", errEmptyDraft
+		}
+		problems := prdraft.Validate(edited, required)
+		if len(problems) == 0 {
+			break
+		}
+
+		fmt.Println(red("[PRBuddy-Go] The draft can't be submitted yet:"))
+		for _, p := range problems {
+			fmt.Printf("  - %s\n", p)
+		}
+		fmt.Print("[PRBuddy-Go] Edit it again?  [Y/n] ")
+		answer, err := reader.ReadString('\n')
+		answer = strings.TrimSpace(strings.ToLower(answer))
+		if err != nil || answer == "n" || answer == "no" {
+			return "", "", fmt.Errorf("draft failed validation; your edit is kept in %s", editPath)
+		}
+		content = prdraft.WithInstructions(edited, problems)
+	}
+
+	title := prdraft.Title(edited)
+	if edited == strings.TrimSpace(string(original)) {
+		return draftPath, title, nil
+	}
+
+	// Keep the edit as a revision so later runs (and 'draft history') see it
+	meta, err := readDraftMeta(dir)
+	if err != nil || meta.DraftResult == nil {
+		if err := utils.WriteFile(editPath, []byte(edited+"\n")); err != nil {
+			return "", "", err
+		}
+		return editPath, title, nil
+	}
+	if err := saveDraftRevision(meta, edited, llm.DraftModeEdited); err != nil {
+		return "", "", fmt.Errorf("could not save the edited draft: %w", err)
+	}
+	fmt.Printf("[PRBuddy-Go] Edited draft saved to: %s\n", draftPath)
+	return draftPath, title, nil
+}
@@ -0,0 +1,184 @@
+// cmd/pr_stack.go
+//
+// Stacked PRs: a branch cut from another feature branch that has an open PR is
+// opened against that branch, and every open PR of the stack lists the others
+// in a "Stack" section that is rewritten whenever one of them is created or updated.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/forge"
+	"github.com/soyuz43/prbuddy-go/internal/prdraft"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// stackParentCandidates returns the local branches the current branch (HEAD) may
+// have been cut from, nearest first: branches HEAD contains that have commits of
+// their own on top of trunk.
+func stackParentCandidates(branch, trunk string) []string {
+	out, err := utils.ExecGit("for-each-ref", "--format=%(refname:short)", "refs/heads/")
+	if err != nil {
+		return nil
+	}
+	trunkRef := trunk
+	if trunk != "" && !branchExists(trunk) {
+		trunkRef = "origin/" + trunk
+	}
+
+	distance := make(map[string]int)
+	var candidates []string
+	for _, name := range strings.Split(out, "\n") {
+		name = strings.TrimSpace(name)
+		if name == "" || name == branch || name == trunk {
+			continue
+		}
+		if _, err := utils.ExecGit("merge-base", "--is-ancestor", name, "HEAD"); err != nil {
+			continue
+		}
+		// Already part of trunk: nothing to stack on
+		if trunkRef != "" {
+			if _, err := utils.ExecGit("merge-base", "--is-ancestor", name, trunkRef); err == nil {
+				continue
+			}
+		}
+		count, err := utils.ExecGit("rev-list", "--count", name+"..HEAD")
+		n, convErr := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || convErr != nil || n == 0 {
+			continue
+		}
+		distance[name] = n
+		candidates = append(candidates, name)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if distance[candidates[i]] != distance[candidates[j]] {
+			return distance[candidates[i]] < distance[candidates[j]]
+		}
+		return candidates[i] < candidates[j]
+	})
+	return candidates
+}
+
+// detectStackParent returns the nearest branch below branch that has an open PR,
+// or "" when branch sits directly on trunk.
+func detectStackParent(client forge.Forge, branch, trunk string) (string, *forge.PullRequest) {
+	for _, candidate := range stackParentCandidates(branch, trunk) {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		pr, err := client.FindPullRequest(ctx, candidate)
+		cancel()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Warning: could not check '%s' for an open PR: %v\n", candidate, err)
+			return "", nil
+		}
+		if pr != nil {
+			return candidate, pr
+		}
+	}
+	return "", nil
+}
+
+// recordedStackParent is detectStackParent without asking the forge: the nearest
+// branch below the current one whose PR prbuddy-go created.
+func recordedStackParent(trunk string) string {
+	branch, err := utils.ExecGit("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return ""
+	}
+	for _, candidate := range stackParentCandidates(strings.TrimSpace(branch), trunk) {
+		if _, err := readPRSync(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// stackBranches returns the branches of branch's stack, bottom first: the
+// branches below it as recorded in pr.json, branch itself, and those stacked on it.
+func stackBranches(branch string) []string {
+	out, _ := utils.ExecGit("for-each-ref", "--format=%(refname:short)", "refs/heads/")
+	bases := make(map[string]string)
+	children := make(map[string][]string)
+	for _, name := range strings.Split(out, "\n") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if sync, err := readPRSync(name); err == nil && sync.Base != "" {
+			bases[name] = sync.Base
+			children[sync.Base] = append(children[sync.Base], name)
+		}
+	}
+
+	seen := map[string]bool{branch: true}
+	stack := []string{branch}
+	for b := bases[branch]; b != "" && !seen[b]; b = bases[b] {
+		if _, err := readPRSync(b); err != nil {
+			break
+		}
+		seen[b] = true
+		stack = append([]string{b}, stack...)
+	}
+
+	var above func(string)
+	above = func(b string) {
+		sort.Strings(children[b])
+		for _, child := range children[b] {
+			if !seen[child] {
+				seen[child] = true
+				stack = append(stack, child)
+				above(child)
+			}
+		}
+	}
+	above(branch)
+	return stack
+}
+
+// syncStack rewrites the "Stack" section of every open PR in branch's stack.
+// Merged or closed PRs drop out of it; a PR left on its own loses the section.
+func syncStack(client forge.Forge, branch string) {
+	branches := stackBranches(branch)
+	if len(branches) < 2 {
+		// A PR that was stacked before may still list the old stack
+		branches = []string{branch}
+	}
+
+	var prs []*forge.PullRequest
+	for _, b := range branches {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		pr, err := client.FindPullRequest(ctx, b)
+		cancel()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Warning: could not update the stack: %v\n", err)
+			return
+		}
+		if pr != nil {
+			prs = append(prs, pr)
+		}
+	}
+
+	for _, pr := range prs {
+		var entries []prdraft.StackEntry
+		for _, other := range prs {
+			entries = append(entries, prdraft.StackEntry{Title: other.Title, URL: other.URL, Current: other.Number == pr.Number})
+		}
+		body := prdraft.WithStack(pr.Body, prdraft.StackSection(entries))
+		if body == pr.Body {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := client.UpdatePullRequest(ctx, pr.Number, pr.Title, body)
+		cancel()
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Warning: could not update the stack of %s: %v\n", pr.URL, err)
+		} else if len(prs) > 1 {
+			fmt.Printf("[PRBuddy-Go] Updated the stack of %s\n", pr.URL)
+		}
+	}
+}
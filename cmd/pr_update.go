@@ -0,0 +1,204 @@
+// cmd/pr_update.go
+//
+// Refreshes the description of the branch's open PR from its draft, keeping the
+// parts reviewers or the author marked and listing what was pushed since.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/forge"
+	"github.com/soyuz43/prbuddy-go/internal/prdraft"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var prRegenerate bool
+
+var prUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the open PR of the current branch from the saved draft",
+	Long: `Replaces the title and description of the branch's open PR (or GitLab merge request) with
+the saved draft, the branch-level one if there is one. --regenerate drafts the whole branch again first.
+
+Parts of the current description between ` + prdraft.KeepStart + ` and ` + prdraft.KeepEnd + `
+are carried over, and a "Changes since last update" section lists the commits pushed since
+the PR was created or last updated by prbuddy-go.`,
+	Args: cobra.NoArgs,
+	Run:  runPRUpdate,
+}
+
+func init() {
+	prUpdateCmd.Flags().BoolVar(&prRegenerate, "regenerate", false, "Regenerate the branch-level draft before updating")
+	prUpdateCmd.Flags().StringVar(&baseBranch, "base", "", "Base branch for --regenerate (default: detected)")
+	prUpdateCmd.Flags().BoolVar(&editDraftFlag, "edit", false, "Review and edit the draft in your editor before updating the PR")
+	prCmd.AddCommand(prUpdateCmd)
+}
+
+func runPRUpdate(cmd *cobra.Command, args []string) {
+	branchName, commitHash, ok := currentBranchAndHead()
+	if !ok {
+		return
+	}
+
+	if _, err := forgeAPI(); err != nil {
+		fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+		return
+	}
+	client, pr := openPullRequest(branchName)
+	if pr == nil {
+		fmt.Printf("[PRBuddy-Go] Branch '%s' has no open PR. Run 'prbuddy-go pr create' first.\n", branchName)
+		return
+	}
+
+	if err := updatePR(client, pr, branchName, commitHash); err != nil {
+		fmt.Printf("[PRBuddy-Go] PR update failed: %v\n", err)
+	}
+}
+
+// openPullRequest returns the open PR from branch, or nil when there is none or
+// the forge can't be asked.
+func openPullRequest(branch string) (forge.Forge, *forge.PullRequest) {
+	client, err := forgeAPI()
+	if err != nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	pr, err := client.FindPullRequest(ctx, branch)
+	if err != nil {
+		fmt.Printf("[PRBuddy-Go] Warning: could not look for an open PR: %v\n", err)
+		return client, nil
+	}
+	return client, pr
+}
+
+// updatePR replaces the description of pr with the branch's draft.
+func updatePR(client forge.Forge, pr *forge.PullRequest, branch, head string) error {
+	var draftPath string
+	if prRegenerate {
+		fmt.Println("[PRBuddy-Go] Regenerating the branch draft...")
+		input, result, err := generateDraftPR(true, baseBranch)
+		if err != nil {
+			return err
+		}
+		dir, err := saveArtifacts(branch, head, input, result)
+		if err != nil {
+			return fmt.Errorf("saving the draft: %w", err)
+		}
+		draftPath = filepath.Join(dir, "draft.md")
+	} else {
+		var err error
+		if draftPath, err = findDraftArtifacts(branch, head); err != nil {
+			return fmt.Errorf("%w; run 'prbuddy-go generate pr --branch' or use --regenerate", err)
+		}
+	}
+
+	var title string
+	if editDraftFlag {
+		var err error
+		if draftPath, title, err = editDraft(draftPath); errors.Is(err, errEmptyDraft) {
+			fmt.Println("[PRBuddy-Go] Aborting PR update due to empty draft.")
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	if title == "" {
+		var err error
+		if title, err = extractPRTitle(draftPath); err != nil {
+			return fmt.Errorf("title extraction: %w", err)
+		}
+	}
+	draft, err := os.ReadFile(draftPath)
+	if err != nil {
+		return err
+	}
+
+	if err := pushBranch(branch); err != nil {
+		return fmt.Errorf("pushing branch: %w", err)
+	}
+
+	body := prdraft.UpdatedBody(string(draft), pr.Body, changesSinceSync(branch, pr.Number, head))
+	body = prdraft.WithStack(body, prdraft.Stack(pr.Body))
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	if err := client.UpdatePullRequest(ctx, pr.Number, title, body); err != nil {
+		return err
+	}
+	record := prSync{Number: pr.Number, URL: pr.URL, Head: head}
+	if previous, err := readPRSync(branch); err == nil && previous.Number == pr.Number {
+		record.Base = previous.Base
+	}
+	if err := writePRSync(branch, record); err != nil {
+		fmt.Printf("[PRBuddy-Go] Warning: could not record the update: %v\n", err)
+	}
+	fmt.Printf("[PRBuddy-Go] PR updated: %s\n", pr.URL)
+	syncStack(client, branch)
+	return nil
+}
+
+// changesSinceSync lists the commits made since prbuddy-go last synced PR number,
+// oldest first, as "subject (sha)".
+func changesSinceSync(branch string, number int, head string) []string {
+	sync, err := readPRSync(branch)
+	if err != nil || sync.Number != number || sync.Head == "" {
+		fmt.Println("[PRBuddy-Go] No record of an earlier update; skipping the changes summary.")
+		return nil
+	}
+	if sync.Head == head {
+		return nil
+	}
+	if _, err := utils.ExecGit("cat-file", "-e", sync.Head+"^{commit}"); err != nil {
+		return []string{fmt.Sprintf("History was rewritten since %s", shortHash(sync.Head))}
+	}
+
+	out, err := utils.ExecGit("log", "--reverse", "--format=%s (%h)", sync.Head+".."+head)
+	if err != nil {
+		fmt.Printf("[PRBuddy-Go] Warning: could not list new commits: %v\n", err)
+		return nil
+	}
+	var changes []string
+	if _, err := utils.ExecGit("merge-base", "--is-ancestor", sync.Head, head); err != nil {
+		changes = append(changes, fmt.Sprintf("History was rewritten since %s", shortHash(sync.Head)))
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			changes = append(changes, line)
+		}
+	}
+	return changes
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// askYesNo asks a yes/no question on stdin. An empty answer means def; no input at
+// all (stdin closed, as in scripts) means no.
+func askYesNo(prompt string, def bool) bool {
+	fmt.Print(prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	switch {
+	case answer == "y" || answer == "yes":
+		return true
+	case answer == "n" || answer == "no":
+		return false
+	case err != nil && answer == "":
+		fmt.Println()
+		return false
+	}
+	return def
+}
@@ -0,0 +1,82 @@
+// cmd/prompts.go
+//
+// Inspect and customise the prompt templates used for drafts, summaries and quickassist.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/soyuz43/prbuddy-go/internal/prompts"
+	"github.com/spf13/cobra"
+)
+
+var promptsOverwrite bool
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "List, show and customise prompt templates",
+	Long: `Prompts are Go text/template files. A template placed in .prbuddy/prompts/<name>.tmpl
+overrides the built-in default of the same name. Available variables:
+  .CommitMessage  .Diff  .DiffHeading  .Branch  .Base  .Files  .Tasks
+  .PRTemplate  .RequiredHeadings  (the repository's pull request template, draft only)
+  .RecentCommits  .Conventional  (recent commit subjects and their convention, commit_msg only)
+and functions: join, trim, lower, upper.`,
+}
+
+var promptsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List prompt templates and where each one is loaded from",
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, name := range prompts.Names() {
+			_, origin, err := prompts.Source(name)
+			if err != nil {
+				fmt.Printf("%-12s %s\n", name, red(err.Error()))
+				continue
+			}
+			if origin != "embedded" {
+				origin = green(origin)
+			}
+			fmt.Printf("%-12s %s\n", bold(name), origin)
+		}
+	},
+}
+
+var promptsShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print the template currently in effect",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		src, origin, err := prompts.Source(args[0])
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		fmt.Println(cyan("# " + origin))
+		fmt.Print(src)
+	},
+}
+
+var promptsInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Export the built-in templates to .prbuddy/prompts for editing",
+	Run: func(cmd *cobra.Command, args []string) {
+		written, err := prompts.Init(promptsOverwrite)
+		for _, path := range written {
+			fmt.Printf("[PRBuddy-Go] Wrote %s\n", path)
+		}
+		if err != nil {
+			fmt.Printf("[PRBuddy-Go] Error: %v\n", err)
+			return
+		}
+		if len(written) == 0 {
+			fmt.Println("[PRBuddy-Go] All templates already exist; use --force to overwrite them.")
+		}
+	},
+}
+
+func init() {
+	promptsInitCmd.Flags().BoolVar(&promptsOverwrite, "force", false, "Overwrite existing template files")
+	promptsCmd.AddCommand(promptsListCmd, promptsShowCmd, promptsInitCmd)
+	rootCmd.AddCommand(promptsCmd)
+}
@@ -4,6 +4,7 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -38,7 +39,7 @@ func handleSingleQuickAssist(query string) {
 	}
 
 	// Use a new conversation (empty ConversationID to generate a new one)
-	response, err := llm.HandleQuickAssist("", query)
+	response, err := llm.HandleQuickAssist(context.Background(), "", query)
 	if err != nil {
 		color.Red("Error: %v\n", err)
 		return
@@ -81,7 +82,7 @@ func StartInteractiveQuickAssist() {
 		}
 
 		// Get response from Quick Assist
-		response, err := llm.HandleQuickAssist(conversationID, query)
+		response, err := llm.HandleQuickAssist(context.Background(), conversationID, query)
 		if err != nil {
 			color.Red("Error: %v\n", err)
 			continue
@@ -28,7 +28,12 @@ var removeCmd = &cobra.Command{
 			fmt.Println("[PRBuddy-Go] Removed the post-commit hook.")
 		}
 
-		// 2. Remove the .git/pr_buddy_db directory
+		// 2. Remove the commit message suggestion from prepare-commit-msg
+		if err := hooks.RemovePrepareCommitMsgHook(); err != nil {
+			fmt.Printf("[PRBuddy-Go] Error removing prepare-commit-msg hook: %v\n", err)
+		}
+
+		// 3. Remove the .git/pr_buddy_db directory
 		repoPath, err := utils.GetRepoPath()
 		if err != nil {
 			fmt.Printf("[PRBuddy-Go] Error retrieving repository path: %v\n", err)
@@ -4,6 +4,7 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -130,7 +131,7 @@ func singleQueryResponse(query string) {
 		return
 	}
 
-	resp, err := llm.HandleQuickAssist("", query)
+	resp, err := llm.HandleQuickAssist(context.Background(), "", query)
 	if err != nil {
 		color.Red("Error: %v\n", err)
 		return
@@ -167,7 +168,7 @@ func startInteractiveQuickAssist(reader *bufio.Reader) {
 			continue
 		}
 
-		resp, err := llm.HandleQuickAssist(conversationID, query)
+		resp, err := llm.HandleQuickAssist(context.Background(), conversationID, query)
 		if err != nil {
 			color.Red("Error: %v\n", err)
 			continue
@@ -289,7 +290,7 @@ func handleDCECommand() {
 		}
 
 		// Regular query: talk to assistant (no DCE re-activation).
-		response, err := llm.HandleQuickAssist(conversationID, input)
+		response, err := llm.HandleQuickAssist(context.Background(), conversationID, input)
 		if err != nil {
 			color.Red("Error processing request: %v", err)
 			continue
@@ -3,6 +3,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/soyuz43/prbuddy-go/internal/llm"
@@ -37,9 +38,9 @@ and provides a natural language summary using the LLM.`,
 
 		if useDCE {
 			fmt.Println("[PRBuddy-Go] Using Dynamic Context Engine for enhanced context awareness")
-			summary, err = llm.GenerateWhatSummaryWithDCEContext()
+			summary, err = llm.GenerateWhatSummaryWithDCEContext(context.Background())
 		} else {
-			summary, err = llm.GenerateWhatSummary()
+			summary, err = llm.GenerateWhatSummary(context.Background())
 		}
 
 		if err != nil {
@@ -0,0 +1,255 @@
+// internal/changelog/changelog.go
+
+package changelog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/conventional"
+)
+
+// DefaultVersion is the heading used for changes that aren't released yet.
+const DefaultVersion = "Unreleased"
+
+// otherType groups commits that don't follow Conventional Commits.
+const otherType = "other"
+
+// hiddenTypes are left out of a changelog unless everything is requested or the
+// commit is breaking; they rarely matter to the people reading release notes.
+var hiddenTypes = map[string]bool{
+	"docs": true, "style": true, "test": true, "build": true, "ci": true, "chore": true,
+}
+
+// Keep a Changelog sections, in the order the format prescribes.
+var sectionOrder = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// sectionFor maps a commit type to its Keep a Changelog section.
+func sectionFor(commitType string) string {
+	switch commitType {
+	case "feat":
+		return "Added"
+	case "fix":
+		return "Fixed"
+	default:
+		return "Changed"
+	}
+}
+
+// Entry is one commit in the changelog, described by its stored PR draft when there is one.
+type Entry struct {
+	Hash          string   `json:"hash"`
+	Type          string   `json:"type"`
+	Scope         string   `json:"scope,omitempty"`
+	Breaking      bool     `json:"breaking,omitempty"`
+	BreakingNotes []string `json:"breaking_notes,omitempty"`
+	Subject       string   `json:"subject"`
+	Title         string   `json:"title,omitempty"`   // draft title, without a Conventional prefix
+	Summary       string   `json:"summary,omitempty"` // draft summary
+}
+
+// NewEntry parses the commit message of hash.
+func NewEntry(hash, message string) Entry {
+	c, ok := conventional.Parse(message)
+	e := Entry{Hash: hash, Type: otherType, Subject: c.Subject}
+	if ok {
+		e.Type, e.Scope, e.Breaking, e.BreakingNotes = c.Type, c.Scope, c.Breaking, c.BreakingNotes
+	}
+	return e
+}
+
+// SetDraft describes the entry with a draft's title and summary.
+func (e *Entry) SetDraft(title, summary string) {
+	title = strings.TrimSpace(title)
+	if c, ok := conventional.Parse(title); ok {
+		title = c.Subject
+	}
+	e.Title, e.Summary = title, strings.TrimSpace(summary)
+}
+
+// Text is the line shown for the entry: the draft title, or else the commit subject.
+func (e Entry) Text() string {
+	if e.Title != "" {
+		return e.Title
+	}
+	return e.Subject
+}
+
+// Group holds the entries of one commit type.
+type Group struct {
+	Type    string  `json:"type"`
+	Entries []Entry `json:"entries"`
+}
+
+// Release is the changelog for one commit range.
+type Release struct {
+	Version string  `json:"version"`
+	Date    string  `json:"date,omitempty"`
+	From    string  `json:"from,omitempty"`
+	To      string  `json:"to"`
+	Groups  []Group `json:"groups"`
+}
+
+// Build groups entries by commit type, most significant type first, keeping commit
+// order within each group. Hidden types are dropped unless all is set.
+func (r *Release) Build(entries []Entry, all bool) {
+	byType := make(map[string][]Entry)
+	var types []string
+	for _, e := range entries {
+		if !all && !e.Breaking && hiddenTypes[e.Type] {
+			continue
+		}
+		if _, ok := byType[e.Type]; !ok {
+			types = append(types, e.Type)
+		}
+		byType[e.Type] = append(byType[e.Type], e)
+	}
+
+	sort.SliceStable(types, func(i, j int) bool {
+		return conventional.Rank(types[i]) < conventional.Rank(types[j])
+	})
+	r.Groups = nil
+	for _, t := range types {
+		r.Groups = append(r.Groups, Group{Type: t, Entries: byType[t]})
+	}
+}
+
+// Heading is the release's "## [version] - date" line.
+func (r Release) Heading() string {
+	version := r.Version
+	if version == "" {
+		version = DefaultVersion
+	}
+	if r.Date == "" || version == DefaultVersion {
+		return fmt.Sprintf("## [%s]", version)
+	}
+	return fmt.Sprintf("## [%s] - %s", version, r.Date)
+}
+
+// Markdown renders the release as a Keep a Changelog section.
+func (r Release) Markdown() string {
+	sections := make(map[string][]string)
+	for _, g := range r.Groups {
+		for _, e := range g.Entries {
+			sections[sectionFor(e.Type)] = append(sections[sectionFor(e.Type)], e.markdown())
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(r.Heading() + "\n")
+	if len(sections) == 0 {
+		b.WriteString("\nNo notable changes.\n")
+	}
+	for _, name := range sectionOrder {
+		if len(sections[name]) == 0 {
+			continue
+		}
+		b.WriteString("\n### " + name + "\n\n")
+		for _, line := range sections[name] {
+			b.WriteString(line + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (e Entry) markdown() string {
+	var b strings.Builder
+	b.WriteString("- ")
+	if e.Breaking {
+		b.WriteString("**BREAKING:** ")
+	}
+	if e.Scope != "" {
+		b.WriteString("**" + e.Scope + ":** ")
+	}
+	b.WriteString(e.Text())
+	if len(e.Hash) >= 7 {
+		b.WriteString(" (" + e.Hash[:7] + ")")
+	}
+	for _, note := range e.BreakingNotes {
+		b.WriteString("\n  - " + note)
+	}
+	return b.String()
+}
+
+// header starts a new CHANGELOG.md.
+const header = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/).
+`
+
+// Prepend adds section above the newest release in an existing CHANGELOG.md (or
+// starts a new one when existing is empty). A release already in the file under the
+// same heading is replaced, so regenerating "Unreleased" doesn't pile up copies.
+func Prepend(existing, section string) string {
+	section = strings.TrimSpace(section)
+	if strings.TrimSpace(existing) == "" {
+		return header + "\n" + section + "\n"
+	}
+
+	lines := strings.Split(existing, "\n")
+	first := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "## ") {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return strings.TrimRight(existing, "\n") + "\n\n" + section + "\n"
+	}
+
+	rest := lines[first:]
+	heading, _, _ := strings.Cut(section, "\n")
+	if sameRelease(rest[0], heading) {
+		end := len(rest)
+		for i := 1; i < len(rest); i++ {
+			if strings.HasPrefix(rest[i], "## ") {
+				end = i
+				break
+			}
+		}
+		rest = rest[end:]
+	}
+
+	out := strings.Join(lines[:first], "\n") + "\n" + section + "\n"
+	if len(rest) > 0 {
+		out += "\n" + strings.Join(rest, "\n")
+	}
+	return strings.TrimRight(out, "\n") + "\n"
+}
+
+// sameRelease compares two "## [version] ..." headings by version only.
+func sameRelease(a, b string) bool {
+	version := func(h string) string {
+		v, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(h), "## "), " ")
+		return strings.ToLower(strings.Trim(v, "[]"))
+	}
+	return version(a) == version(b)
+}
+
+// DraftText pulls the title (first H1) and summary (first paragraph of prose) out of
+// a markdown draft, for drafts saved without a structured draft.json.
+func DraftText(markdown string) (title, summary string) {
+	var paragraph []string
+	for _, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case title == "" && strings.HasPrefix(line, "# "):
+			title = strings.TrimSpace(strings.TrimPrefix(line, "# "))
+		case line == "":
+			if len(paragraph) > 0 {
+				return title, strings.Join(paragraph, " ")
+			}
+		case strings.HasPrefix(line, "#"), strings.HasPrefix(line, ">"), strings.HasPrefix(line, "- "):
+			if len(paragraph) > 0 {
+				return title, strings.Join(paragraph, " ")
+			}
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	return title, strings.Join(paragraph, " ")
+}
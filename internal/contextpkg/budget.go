@@ -0,0 +1,243 @@
+// internal/contextpkg/budget.go
+package contextpkg
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// -----------------------------------------------------------------------------
+// Token Estimation
+// -----------------------------------------------------------------------------
+
+// charsPerToken is a deliberately conservative average for code and prose;
+// real tokenizers usually land between 3.5 and 4.5 characters per token.
+const charsPerToken = 3.5
+
+// perMessageOverhead approximates the role/formatting tokens chat templates add.
+const perMessageOverhead = 4
+
+// EstimateTokens returns an approximate token count for text.
+func EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return int(float64(utf8.RuneCountInString(text))/charsPerToken) + 1
+}
+
+// EstimateMessageTokens returns an approximate token count for a single message.
+func EstimateMessageTokens(m Message) int {
+	return EstimateTokens(m.Content) + perMessageOverhead
+}
+
+// EstimateContextTokens returns an approximate token count for a whole context.
+func EstimateContextTokens(messages []Message) int {
+	total := 0
+	for _, m := range messages {
+		total += EstimateMessageTokens(m)
+	}
+	return total
+}
+
+// -----------------------------------------------------------------------------
+// Budgeting
+// -----------------------------------------------------------------------------
+
+// Budget describes the token window of a model and how much of it is kept for the reply.
+type Budget struct {
+	ContextLength      int // Total window (num_ctx)
+	ReserveForResponse int // Tokens left free for the model's answer
+}
+
+// Available returns how many tokens the prompt may use.
+func (b Budget) Available() int {
+	available := b.ContextLength - b.ReserveForResponse
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+// BudgetReport records what had to be removed to make a context fit.
+type BudgetReport struct {
+	ContextLength int
+	Limit         int
+	Before        int
+	After         int
+	Dropped       []string
+}
+
+// Trimmed reports whether anything was removed.
+func (r BudgetReport) Trimmed() bool {
+	return len(r.Dropped) > 0
+}
+
+// Summary returns a one-line human readable description of the trimming.
+func (r BudgetReport) Summary() string {
+	if !r.Trimmed() {
+		return fmt.Sprintf("context fits (%d/%d tokens)", r.After, r.Limit)
+	}
+	return fmt.Sprintf("context trimmed from ~%d to ~%d tokens (limit %d): %s",
+		r.Before, r.After, r.Limit, strings.Join(r.Dropped, "; "))
+}
+
+// IsDCELogMessage reports whether m is one of the "[DCE] ..." progress lines added to conversations.
+func IsDCELogMessage(m Message) bool {
+	return m.Role == "system" && strings.HasPrefix(m.Content, "[DCE]")
+}
+
+// IsDCESnapshotMessage reports whether m is the DCE task/context snapshot injected by AugmentContext.
+func IsDCESnapshotMessage(m Message) bool {
+	return m.Role == "system" && strings.Contains(m.Content, "ACTIVE DEVELOPMENT CONTEXT")
+}
+
+// FitMessages trims messages until they fit budget, in order of expendability:
+//  1. DCE progress log lines
+//  2. the oldest chat history (the final message is always kept)
+//  3. the DCE snapshot, condensed
+//  4. the largest remaining message (typically the one carrying the diff), truncated
+//
+// The input slice is never modified.
+func FitMessages(messages []Message, budget Budget) ([]Message, BudgetReport) {
+	limit := budget.Available()
+	out := append([]Message(nil), messages...)
+	used := EstimateContextTokens(out)
+
+	report := BudgetReport{ContextLength: budget.ContextLength, Limit: limit, Before: used}
+	if used <= limit || len(out) == 0 {
+		report.After = used
+		return out, report
+	}
+
+	// 1) DCE log lines
+	out, used = dropOldest(out, used, limit, IsDCELogMessage, &report, "DCE log message(s)")
+
+	// 2) Oldest history: anything but system prompts and the final message
+	last := len(out) - 1
+	isHistory := func(i int, m Message) bool { return i < last && m.Role != "system" }
+	out, used = dropOldestIndexed(out, used, limit, isHistory, &report, "oldest chat message(s)")
+
+	// 3) Condense the DCE snapshot
+	for i := range out {
+		if used <= limit {
+			break
+		}
+		if IsDCESnapshotMessage(out[i]) {
+			before := EstimateMessageTokens(out[i])
+			target := before - (used - limit)
+			if target < 256 {
+				target = 256
+			}
+			out[i].Content = TruncateToTokens(out[i].Content, target)
+			after := EstimateMessageTokens(out[i])
+			if after < before {
+				used -= before - after
+				report.Dropped = append(report.Dropped,
+					fmt.Sprintf("condensed DCE snapshot from ~%d to ~%d tokens", before, after))
+			}
+		}
+	}
+
+	// 4) Truncate the largest message until we fit (bounded passes)
+	for pass := 0; pass < 3 && used > limit; pass++ {
+		idx, largest := -1, 0
+		for i, m := range out {
+			if t := EstimateMessageTokens(m); t > largest {
+				idx, largest = i, t
+			}
+		}
+		if idx < 0 {
+			break
+		}
+		target := largest - (used - limit)
+		if target < 64 {
+			target = 64
+		}
+		out[idx].Content = TruncateToTokens(out[idx].Content, target)
+		after := EstimateMessageTokens(out[idx])
+		if after >= largest {
+			break
+		}
+		used -= largest - after
+		report.Dropped = append(report.Dropped,
+			fmt.Sprintf("truncated %s message from ~%d to ~%d tokens", out[idx].Role, largest, after))
+	}
+
+	report.After = used
+	return out, report
+}
+
+func dropOldest(messages []Message, used, limit int, match func(Message) bool, report *BudgetReport, label string) ([]Message, int) {
+	return dropOldestIndexed(messages, used, limit, func(_ int, m Message) bool { return match(m) }, report, label)
+}
+
+// dropOldestIndexed removes matching messages from the front until used <= limit.
+func dropOldestIndexed(messages []Message, used, limit int, match func(int, Message) bool, report *BudgetReport, label string) ([]Message, int) {
+	if used <= limit {
+		return messages, used
+	}
+
+	kept := make([]Message, 0, len(messages))
+	dropped := 0
+	for i, m := range messages {
+		if used > limit && match(i, m) {
+			used -= EstimateMessageTokens(m)
+			dropped++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	if dropped > 0 {
+		report.Dropped = append(report.Dropped, fmt.Sprintf("dropped %d %s", dropped, label))
+	}
+	return kept, used
+}
+
+// TruncateToTokens keeps whole lines from the start of text until roughly maxTokens
+// are used, then appends a marker saying how many lines were dropped. A first line
+// too long to keep whole is cut instead, and the marker says so.
+func TruncateToTokens(text string, maxTokens int) string {
+	if EstimateTokens(text) <= maxTokens {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	marker := func(n int, cut bool) string {
+		if cut {
+			return fmt.Sprintf("[... line cut and %d more lines truncated to fit the model context ...]", n)
+		}
+		return fmt.Sprintf("[... %d lines truncated to fit the model context ...]", n)
+	}
+	remaining := maxTokens - EstimateTokens(marker(len(lines), true))
+
+	var b strings.Builder
+	kept := 0
+	cut := false
+	for _, line := range lines {
+		cost := EstimateTokens(line) + 1
+		if cost > remaining {
+			// A single huge line (minified file, lockfile) gets cut mid-line
+			if kept == 0 && remaining > 0 {
+				maxChars := int(float64(remaining) * charsPerToken)
+				if maxChars < len(line) {
+					b.WriteString(strings.ToValidUTF8(line[:maxChars], ""))
+					b.WriteString("\n")
+					cut = true
+				}
+			}
+			break
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+		remaining -= cost
+		kept++
+	}
+
+	if cut {
+		b.WriteString(marker(len(lines)-1, true))
+	} else {
+		b.WriteString(marker(len(lines)-kept, false))
+	}
+	return b.String()
+}
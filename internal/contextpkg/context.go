@@ -3,7 +3,6 @@ package contextpkg
 
 import (
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 )
@@ -32,6 +31,8 @@ type Task struct {
 // Conversation represents a single conversation thread.
 type Conversation struct {
 	ID             string
+	Title          string   // set by the user; see Rename
+	Tags           []string // set by the user; see SetTags
 	Ephemeral      bool
 	InitialDiff    string
 	Messages       []Message
@@ -88,17 +89,17 @@ func (cm *ConversationManager) RemoveConversation(id string) {
 	delete(cm.conversations, id)
 }
 
+// Count returns the number of conversations in memory.
+func (cm *ConversationManager) Count() int {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+	return len(cm.conversations)
+}
+
 // Cleanup removes conversations that have been inactive for longer than maxAge.
+// See Expire for budgets and persisting conversations before they go.
 func (cm *ConversationManager) Cleanup(maxAge time.Duration) {
-	cm.mutex.Lock()
-	defer cm.mutex.Unlock()
-
-	now := time.Now()
-	for id, conv := range cm.conversations {
-		if now.Sub(conv.LastActivity) > maxAge {
-			delete(cm.conversations, id)
-		}
-	}
+	cm.Expire(ExpiryPolicy{MaxIdle: maxAge}, nil)
 }
 
 // AddMessage appends a new message to the conversation.
@@ -141,15 +142,6 @@ func GenerateConversationID(prefix string) string {
 	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
 }
 
-// TruncateDiff reduces the diff size to at most maxLines while preserving key information.
-func TruncateDiff(diff string, maxLines int) string {
-	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
-	if len(lines) <= maxLines {
-		return diff
-	}
-	return strings.Join(lines[:maxLines], "\n")
-}
-
 // ConversationManagerInstance is a global singleton instance of ConversationManager.
 var ConversationManagerInstance = NewConversationManager()
 
@@ -0,0 +1,331 @@
+// internal/contextpkg/diff_condenser.go
+package contextpkg
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// Unified Diff Parsing
+// -----------------------------------------------------------------------------
+
+// DiffHunk is a single "@@ ... @@" block of a file diff.
+type DiffHunk struct {
+	Header string
+	Lines  []string
+}
+
+// DiffFile is the diff of a single file: its header lines plus hunks.
+type DiffFile struct {
+	Path    string
+	Header  []string // "diff --git", "index", "---", "+++", rename/mode lines
+	Hunks   []DiffHunk
+	Added   int
+	Removed int
+	Binary  bool
+}
+
+var hunkHeaderRe = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+\d+(?:,(\d+))? @@`)
+
+// ParseUnifiedDiff splits git's unified diff output into files and hunks. Lines that
+// belong to no file (section banners, untracked file lists) are returned separately.
+func ParseUnifiedDiff(diff string) ([]DiffFile, []string) {
+	var files []DiffFile
+	var other []string
+	var cur *DiffFile
+	oldLeft, newLeft := 0, 0 // lines still expected in the current hunk
+
+	flush := func() {
+		if cur != nil {
+			files = append(files, *cur)
+			cur = nil
+		}
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		// Inside a hunk: consume exactly the advertised line counts
+		if cur != nil && len(cur.Hunks) > 0 && (oldLeft > 0 || newLeft > 0) {
+			h := &cur.Hunks[len(cur.Hunks)-1]
+			switch {
+			case strings.HasPrefix(line, "+"):
+				newLeft--
+				cur.Added++
+			case strings.HasPrefix(line, "-"):
+				oldLeft--
+				cur.Removed++
+			case strings.HasPrefix(line, `\`):
+				// "\ No newline at end of file"
+			default:
+				oldLeft--
+				newLeft--
+			}
+			h.Lines = append(h.Lines, line)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			cur = &DiffFile{Path: pathFromGitHeader(line), Header: []string{line}}
+		case cur != nil && strings.HasPrefix(line, "@@"):
+			m := hunkHeaderRe.FindStringSubmatch(line)
+			if m == nil {
+				cur.Header = append(cur.Header, line)
+				continue
+			}
+			oldLeft, newLeft = hunkCount(m[1]), hunkCount(m[2])
+			cur.Hunks = append(cur.Hunks, DiffHunk{Header: line})
+		case cur != nil && len(cur.Hunks) == 0 && line != "":
+			cur.Header = append(cur.Header, line)
+			if p := strings.TrimPrefix(line, "+++ b/"); p != line {
+				cur.Path = p
+			} else if strings.HasPrefix(line, "Binary files ") {
+				cur.Binary = true
+			}
+		case line != "":
+			// Text after the last hunk that doesn't start a new file isn't part of any diff
+			flush()
+			other = append(other, line)
+		}
+	}
+	flush()
+	return files, other
+}
+
+func hunkCount(s string) int {
+	if s == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// String renders the file back into unified diff form.
+func (f DiffFile) String() string {
+	var b strings.Builder
+	b.WriteString(strings.Join(f.Header, "\n") + "\n")
+	for _, h := range f.Hunks {
+		b.WriteString(h.Header + "\n")
+		if len(h.Lines) > 0 {
+			b.WriteString(strings.Join(h.Lines, "\n") + "\n")
+		}
+	}
+	return b.String()
+}
+
+// pathFromGitHeader extracts the b/ path from "diff --git a/x b/x".
+func pathFromGitHeader(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		return rest[i+3:]
+	}
+	return rest
+}
+
+// -----------------------------------------------------------------------------
+// Noise Classification
+// -----------------------------------------------------------------------------
+
+var lockfiles = map[string]bool{
+	"go.sum": true, "package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"Cargo.lock": true, "poetry.lock": true, "Pipfile.lock": true, "Gemfile.lock": true,
+	"composer.lock": true, "mix.lock": true, "pubspec.lock": true, "flake.lock": true,
+}
+
+var generatedSuffixes = []string{
+	".pb.go", "_gen.go", ".gen.go", "_generated.go", ".min.js", ".min.css", ".js.map", ".css.map",
+	"_pb2.py", "_pb2_grpc.py",
+}
+
+var vendoredDirs = []string{"vendor/", "node_modules/", "third_party/", "bower_components/"}
+
+// NoiseReason classifies files that rarely help an LLM understand a change.
+// It returns "lockfile", "generated", "vendored", or "" for regular files.
+func NoiseReason(filePath string) string {
+	base := path.Base(filePath)
+	if lockfiles[base] {
+		return "lockfile"
+	}
+	for _, dir := range vendoredDirs {
+		if strings.HasPrefix(filePath, dir) || strings.Contains(filePath, "/"+dir) {
+			return "vendored"
+		}
+	}
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return "generated"
+		}
+	}
+	if strings.HasPrefix(base, "zz_generated") {
+		return "generated"
+	}
+	return ""
+}
+
+// -----------------------------------------------------------------------------
+// Condensing
+// -----------------------------------------------------------------------------
+
+// CondensedDiff is the outcome of CondenseDiff.
+type CondensedDiff struct {
+	Text         string
+	Files        []DiffFile // every parsed file, noise included
+	OmittedHunks int
+	CutHunks     int      // hunks kept only in part because they alone exceeded the budget
+	SkippedFiles []string // lockfiles, generated and vendored files
+}
+
+// Lossy reports whether any non-noise content had to be left out.
+func (c CondensedDiff) Lossy() bool {
+	return c.OmittedHunks > 0 || c.CutHunks > 0
+}
+
+// CondenseDiff rewrites a unified diff to fit roughly maxTokens. The result always
+// starts with a per-file stat header; lockfiles, generated and vendored files are
+// listed there but their contents dropped. Remaining hunks are added whole,
+// round-robin across files, so later files are represented even on huge commits;
+// whatever doesn't fit is summarised as "N more hunks omitted in X".
+func CondenseDiff(diff string, maxTokens int) CondensedDiff {
+	files, other := ParseUnifiedDiff(diff)
+	result := CondensedDiff{Files: files}
+
+	var relevant []int
+	for i, f := range files {
+		if NoiseReason(f.Path) != "" {
+			result.SkippedFiles = append(result.SkippedFiles, f.Path)
+			continue
+		}
+		relevant = append(relevant, i)
+	}
+
+	stat := DiffStatHeader(files)
+	remaining := maxTokens - EstimateTokens(stat)
+
+	// included[i] = number of leading hunks of files[i] kept; partial[i] marks a cut hunk
+	included := make([]int, len(files))
+	partial := make(map[int]string)
+	headerPaid := make([]bool, len(files))
+
+	for round := 0; ; round++ {
+		progress := false
+		pending := 0
+		for _, i := range relevant {
+			if included[i] == round && round < len(files[i].Hunks) {
+				pending++
+			}
+		}
+
+		for _, i := range relevant {
+			f := files[i]
+			if included[i] != round || round >= len(f.Hunks) {
+				continue
+			}
+			if _, cut := partial[i]; cut {
+				continue
+			}
+
+			cost := EstimateTokens(strings.Join(f.Hunks[round].Lines, "\n")) + EstimateTokens(f.Hunks[round].Header)
+			if !headerPaid[i] {
+				cost += EstimateTokens(strings.Join(f.Header, "\n"))
+			}
+
+			if cost <= remaining {
+				remaining -= cost
+				included[i]++
+				headerPaid[i] = true
+				progress = true
+			} else if round == 0 && remaining > 200 {
+				// A first hunk that's too big still gets a fair share, cut at a line boundary
+				share := remaining / pending
+				body := TruncateToTokens(strings.Join(f.Hunks[0].Lines, "\n"), share)
+				partial[i] = body
+				remaining -= EstimateTokens(body) + EstimateTokens(strings.Join(f.Header, "\n"))
+				included[i] = 1
+				headerPaid[i] = true
+			}
+			pending--
+		}
+		if !progress {
+			break
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(stat)
+	for _, i := range relevant {
+		f := files[i]
+		omitted := len(f.Hunks) - included[i]
+		if included[i] == 0 {
+			if len(f.Hunks) > 0 {
+				result.OmittedHunks += omitted
+				b.WriteString(fmt.Sprintf("\n[... %d hunks omitted in %s ...]\n", omitted, f.Path))
+			} else {
+				b.WriteString("\n" + strings.Join(f.Header, "\n") + "\n")
+			}
+			continue
+		}
+
+		b.WriteString("\n" + strings.Join(f.Header, "\n") + "\n")
+		for h := 0; h < included[i]; h++ {
+			b.WriteString(f.Hunks[h].Header + "\n")
+			if body, ok := partial[i]; ok && h == 0 {
+				b.WriteString(body + "\n")
+				continue
+			}
+			b.WriteString(strings.Join(f.Hunks[h].Lines, "\n") + "\n")
+		}
+		if omitted > 0 {
+			result.OmittedHunks += omitted
+			b.WriteString(fmt.Sprintf("[... %d more hunks omitted in %s ...]\n", omitted, f.Path))
+		}
+	}
+
+	if notes := otherDiffContent(other); notes != "" {
+		b.WriteString("\n" + TruncateToTokens(notes, max(remaining, 64)) + "\n")
+	}
+
+	result.CutHunks = len(partial)
+	result.Text = b.String()
+	return result
+}
+
+// DiffStatHeader renders a "git diff --stat"-like summary of every file.
+func DiffStatHeader(files []DiffFile) string {
+	added, removed := 0, 0
+	for _, f := range files {
+		added += f.Added
+		removed += f.Removed
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Changed files (%d files, +%d -%d):\n", len(files), added, removed))
+	for _, f := range files {
+		line := fmt.Sprintf("  %s | +%d -%d", f.Path, f.Added, f.Removed)
+		if f.Binary {
+			line = fmt.Sprintf("  %s | binary", f.Path)
+		}
+		if reason := NoiseReason(f.Path); reason != "" {
+			line += fmt.Sprintf(" (%s, contents omitted)", reason)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// otherDiffContent keeps non-diff lines worth showing (e.g. untracked file lists),
+// dropping the "--- Staged Changes ---" style banners that lose meaning once condensed.
+func otherDiffContent(lines []string) string {
+	var kept []string
+	for _, line := range lines {
+		t := strings.TrimSpace(line)
+		if strings.HasPrefix(t, "--- ") && strings.HasSuffix(t, " ---") && !strings.Contains(t, "Untracked") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,120 @@
+// internal/contextpkg/expiry.go
+package contextpkg
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ExpiryPolicy bounds the conversations a long-running process keeps in memory.
+// Zero fields are unlimited.
+type ExpiryPolicy struct {
+	MaxIdle     time.Duration // Remove conversations inactive for longer
+	MaxMessages int           // Total messages across all conversations
+	MaxBytes    int           // Total size of message contents and initial diffs
+}
+
+// Eviction is a conversation removed by Expire.
+type Eviction struct {
+	Snapshot ConversationSnapshot
+	Reason   string
+}
+
+// conversationUsage is what Expire needs of a conversation, read under its lock.
+type conversationUsage struct {
+	conv         *Conversation
+	lastActivity time.Time
+	messages     int
+	bytes        int
+}
+
+// Expire removes the conversations idle for longer than policy.MaxIdle, then the
+// least recently active ones until the rest fit the message and byte budgets.
+//
+// beforeEvict, when not nil, is called with a copy of each conversation before it
+// is removed; a conversation it fails on is kept until the next call. A
+// conversation that sees activity meanwhile is kept too.
+func (cm *ConversationManager) Expire(policy ExpiryPolicy, beforeEvict func(ConversationSnapshot) error) []Eviction {
+	cm.mutex.RLock()
+	usages := make([]conversationUsage, 0, len(cm.conversations))
+	for _, conv := range cm.conversations {
+		usages = append(usages, conv.usage())
+	}
+	cm.mutex.RUnlock()
+
+	// Oldest first: they are the idle ones and the first to go over budget
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].lastActivity.Before(usages[j].lastActivity)
+	})
+	var totalMessages, totalBytes int
+	for _, u := range usages {
+		totalMessages += u.messages
+		totalBytes += u.bytes
+	}
+
+	now := time.Now()
+	var evicted []Eviction
+	for _, u := range usages {
+		var reason string
+		switch idle := now.Sub(u.lastActivity); {
+		case policy.MaxIdle > 0 && idle > policy.MaxIdle:
+			reason = fmt.Sprintf("idle for %s", idle.Round(time.Second))
+		case policy.MaxMessages > 0 && totalMessages > policy.MaxMessages:
+			reason = fmt.Sprintf("over the budget of %d messages", policy.MaxMessages)
+		case policy.MaxBytes > 0 && totalBytes > policy.MaxBytes:
+			reason = fmt.Sprintf("over the budget of %d bytes", policy.MaxBytes)
+		default:
+			// The rest are more recent and fit the budgets
+			return evicted
+		}
+
+		snapshot := u.conv.Snapshot()
+		if beforeEvict != nil {
+			if err := beforeEvict(snapshot); err != nil {
+				continue
+			}
+		}
+		if !cm.removeIfIdleSince(u.conv, u.lastActivity) {
+			continue
+		}
+		totalMessages -= u.messages
+		totalBytes -= u.bytes
+		evicted = append(evicted, Eviction{Snapshot: snapshot, Reason: reason})
+	}
+	return evicted
+}
+
+// removeIfIdleSince removes conv unless it was replaced or active after lastActivity.
+func (cm *ConversationManager) removeIfIdleSince(conv *Conversation, lastActivity time.Time) bool {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	if cm.conversations[conv.ID] != conv {
+		return false
+	}
+	conv.mutex.RLock()
+	active := conv.LastActivity.After(lastActivity)
+	conv.mutex.RUnlock()
+	if active {
+		return false
+	}
+	delete(cm.conversations, conv.ID)
+	return true
+}
+
+func (c *Conversation) usage() conversationUsage {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	u := conversationUsage{
+		conv:         c,
+		lastActivity: c.LastActivity,
+		messages:     len(c.Messages),
+		bytes:        len(c.InitialDiff),
+	}
+	for _, msg := range c.Messages {
+		u.bytes += len(msg.Content)
+	}
+	return u
+}
@@ -0,0 +1,149 @@
+// internal/contextpkg/snapshot.go
+package contextpkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConversationSnapshot is a copy of a conversation taken under its lock, safe to
+// read while the conversation goes on.
+type ConversationSnapshot struct {
+	ID           string
+	Title        string
+	Tags         []string
+	Ephemeral    bool
+	ActiveDCE    bool
+	Messages     []Message
+	Tasks        []Task
+	LastActivity time.Time
+}
+
+// Snapshot copies the conversation.
+func (c *Conversation) Snapshot() ConversationSnapshot {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return ConversationSnapshot{
+		ID:           c.ID,
+		Title:        c.Title,
+		Tags:         append([]string(nil), c.Tags...),
+		Ephemeral:    c.Ephemeral,
+		ActiveDCE:    c.IsActiveDCE,
+		Messages:     append([]Message(nil), c.Messages...),
+		Tasks:        append([]Task(nil), c.Tasks...),
+		LastActivity: c.LastActivity,
+	}
+}
+
+// Snapshot copies the conversation with the given id.
+func (cm *ConversationManager) Snapshot(id string) (ConversationSnapshot, bool) {
+	conv, exists := cm.GetConversation(id)
+	if !exists {
+		return ConversationSnapshot{}, false
+	}
+	return conv.Snapshot(), true
+}
+
+// Snapshots copies every conversation, most recently active first.
+func (cm *ConversationManager) Snapshots() []ConversationSnapshot {
+	cm.mutex.RLock()
+	convs := make([]*Conversation, 0, len(cm.conversations))
+	for _, conv := range cm.conversations {
+		convs = append(convs, conv)
+	}
+	cm.mutex.RUnlock()
+
+	snapshots := make([]ConversationSnapshot, 0, len(convs))
+	for _, conv := range convs {
+		snapshots = append(snapshots, conv.Snapshot())
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if !snapshots[i].LastActivity.Equal(snapshots[j].LastActivity) {
+			return snapshots[i].LastActivity.After(snapshots[j].LastActivity)
+		}
+		return snapshots[i].ID < snapshots[j].ID
+	})
+	return snapshots
+}
+
+// Rename sets the title of the conversation with the given id; false when there is none.
+func (cm *ConversationManager) Rename(id, title string) bool {
+	conv, exists := cm.GetConversation(id)
+	if !exists {
+		return false
+	}
+	conv.mutex.Lock()
+	defer conv.mutex.Unlock()
+	conv.Title = strings.TrimSpace(title)
+	return true
+}
+
+// SetTags replaces the tags of the conversation with the given id, dropping blanks
+// and duplicates; false when there is none.
+func (cm *ConversationManager) SetTags(id string, tags []string) bool {
+	conv, exists := cm.GetConversation(id)
+	if !exists {
+		return false
+	}
+	var cleaned []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" && !seen[tag] {
+			seen[tag] = true
+			cleaned = append(cleaned, tag)
+		}
+	}
+
+	conv.mutex.Lock()
+	defer conv.mutex.Unlock()
+	conv.Tags = cleaned
+	return true
+}
+
+// HasTag reports whether the snapshot carries tag.
+func (s ConversationSnapshot) HasTag(tag string) bool {
+	for _, t := range s.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Markdown renders the conversation for reading or sharing: a header with its
+// metadata, then every message under a heading naming its role.
+func (s ConversationSnapshot) Markdown() string {
+	var b strings.Builder
+	title := s.Title
+	if title == "" {
+		title = s.ID
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "- ID: `%s`\n", s.ID)
+	if len(s.Tags) > 0 {
+		fmt.Fprintf(&b, "- Tags: %s\n", strings.Join(s.Tags, ", "))
+	}
+	fmt.Fprintf(&b, "- Last activity: %s\n", s.LastActivity.UTC().Format(time.RFC3339))
+	if s.Ephemeral {
+		b.WriteString("- Ephemeral: yes\n")
+	}
+
+	if len(s.Tasks) > 0 {
+		b.WriteString("\n## Tasks\n\n")
+		for _, task := range s.Tasks {
+			fmt.Fprintf(&b, "- %s\n", task.Description)
+		}
+	}
+
+	for _, msg := range s.Messages {
+		role := msg.Role
+		if role != "" {
+			role = strings.ToUpper(role[:1]) + role[1:]
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", role, strings.TrimSpace(msg.Content))
+	}
+	return b.String()
+}
@@ -0,0 +1,235 @@
+// internal/conventional/conventional.go
+
+package conventional
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Commit is a commit message parsed per the Conventional Commits 1.0 spec.
+type Commit struct {
+	Type          string
+	Scope         string
+	Breaking      bool
+	Subject       string
+	Body          string
+	BreakingNotes []string // text of BREAKING CHANGE footers
+}
+
+var (
+	headerRe = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s+(.+)$`)
+	footerRe = regexp.MustCompile(`^(BREAKING[ -]CHANGE|[\w-]+)(?::\s|\s#)(.*)$`)
+)
+
+// Parse parses message. ok is false when the header isn't a Conventional Commit.
+func Parse(message string) (Commit, bool) {
+	message = strings.TrimSpace(message)
+	header, rest, _ := strings.Cut(message, "\n")
+	m := headerRe.FindStringSubmatch(strings.TrimSpace(header))
+	if m == nil {
+		return Commit{Subject: strings.TrimSpace(header), Body: strings.TrimSpace(rest)}, false
+	}
+
+	c := Commit{
+		Type:     strings.ToLower(m[1]),
+		Scope:    strings.TrimSpace(m[2]),
+		Breaking: m[3] == "!",
+		Subject:  strings.TrimSpace(m[4]),
+		Body:     strings.TrimSpace(rest),
+	}
+	c.BreakingNotes = breakingFooters(c.Body)
+	if len(c.BreakingNotes) > 0 {
+		c.Breaking = true
+	}
+	return c, true
+}
+
+// IsFooter reports whether line starts a footer (a git trailer), e.g. "Refs: #42",
+// "Closes #7" or "BREAKING CHANGE: ...".
+func IsFooter(line string) bool {
+	return footerRe.MatchString(line)
+}
+
+// breakingFooters collects "BREAKING CHANGE: ..." footers, including continuation lines.
+func breakingFooters(body string) []string {
+	var notes []string
+	current := -1
+	for _, line := range strings.Split(body, "\n") {
+		if m := footerRe.FindStringSubmatch(line); m != nil {
+			current = -1
+			if m[1] == "BREAKING CHANGE" || m[1] == "BREAKING-CHANGE" {
+				notes = append(notes, strings.TrimSpace(m[2]))
+				current = len(notes) - 1
+			}
+			continue
+		}
+		if current >= 0 && strings.TrimSpace(line) != "" {
+			notes[current] += " " + strings.TrimSpace(line)
+		}
+	}
+	return notes
+}
+
+// typePrecedence orders types by how much they say about a PR: a branch with a feat
+// and three fixes is a feature.
+var typePrecedence = []string{"feat", "fix", "perf", "refactor", "revert", "docs", "test", "build", "ci", "style", "chore"}
+
+// Rank orders commit types by significance, lowest first; unknown types come last.
+func Rank(commitType string) int {
+	for i, p := range typePrecedence {
+		if p == commitType {
+			return i
+		}
+	}
+	return len(typePrecedence)
+}
+
+// typeLabels maps commit types to the GitHub labels they suggest.
+var typeLabels = map[string]string{
+	"feat":     "enhancement",
+	"fix":      "bug",
+	"perf":     "performance",
+	"refactor": "refactor",
+	"revert":   "revert",
+	"docs":     "documentation",
+	"test":     "tests",
+	"build":    "build",
+	"ci":       "ci",
+	"chore":    "chore",
+}
+
+// BreakingLabel is suggested whenever any commit is marked breaking.
+const BreakingLabel = "breaking-change"
+
+// Analysis summarises the Conventional Commits of a PR.
+type Analysis struct {
+	Commits  []Commit // only the commits that parsed
+	Type     string   // dominant type, by typePrecedence
+	Scope    string   // shared scope, if the commits agree on one
+	Breaking bool
+}
+
+// Analyze parses every message and works out the PR's overall type and scope.
+func Analyze(messages []string) Analysis {
+	var a Analysis
+	for _, msg := range messages {
+		if c, ok := Parse(msg); ok {
+			a.Commits = append(a.Commits, c)
+			a.Breaking = a.Breaking || c.Breaking
+		}
+	}
+	if len(a.Commits) == 0 {
+		return a
+	}
+
+	a.Type = a.Commits[0].Type
+	for _, c := range a.Commits[1:] {
+		if Rank(c.Type) < Rank(a.Type) {
+			a.Type = c.Type
+		}
+	}
+
+	scopes := make(map[string]bool)
+	for _, c := range a.Commits {
+		if c.Type == a.Type {
+			scopes[c.Scope] = true
+		}
+	}
+	if len(scopes) == 1 {
+		for s := range scopes {
+			a.Scope = s
+		}
+	}
+	return a
+}
+
+// Conventional reports whether any commit followed the convention.
+func (a Analysis) Conventional() bool {
+	return len(a.Commits) > 0
+}
+
+// Followed reports whether most of messages are Conventional Commits, i.e. whether a
+// repository's history suggests new commits should be too.
+func Followed(messages []string) bool {
+	if len(messages) == 0 {
+		return false
+	}
+	return 2*len(Analyze(messages).Commits) >= len(messages)
+}
+
+// Title prefixes title with the PR's type and scope, e.g. "feat(dce)!: add refresh".
+// A title that already carries a Conventional prefix has it replaced.
+func (a Analysis) Title(title string) string {
+	title = strings.TrimSpace(title)
+	if !a.Conventional() {
+		return title
+	}
+	if c, ok := Parse(title); ok {
+		title = c.Subject
+	}
+
+	prefix := a.Type
+	if a.Scope != "" {
+		prefix += "(" + a.Scope + ")"
+	}
+	if a.Breaking {
+		prefix += "!"
+	}
+	return fmt.Sprintf("%s: %s", prefix, title)
+}
+
+// Labels suggests GitHub labels for every commit type present, sorted.
+func (a Analysis) Labels() []string {
+	set := make(map[string]bool)
+	for _, c := range a.Commits {
+		if label, ok := typeLabels[c.Type]; ok {
+			set[label] = true
+		}
+	}
+	if a.Breaking {
+		set[BreakingLabel] = true
+	}
+	labels := make([]string, 0, len(set))
+	for l := range set {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// BreakingNotes returns every BREAKING CHANGE footer, or the subjects of commits
+// marked breaking with "!" but no footer.
+func (a Analysis) BreakingNotes() []string {
+	var notes []string
+	for _, c := range a.Commits {
+		switch {
+		case len(c.BreakingNotes) > 0:
+			notes = append(notes, c.BreakingNotes...)
+		case c.Breaking:
+			notes = append(notes, c.Subject)
+		}
+	}
+	return notes
+}
+
+// BreakingCallout renders a GitHub warning alert listing the breaking changes,
+// or "" when there are none.
+func (a Analysis) BreakingCallout() string {
+	notes := a.BreakingNotes()
+	if len(notes) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("> [!WARNING]\n> **BREAKING CHANGE**")
+	if len(notes) == 1 {
+		b.WriteString(": " + notes[0])
+		return b.String()
+	}
+	for _, n := range notes {
+		b.WriteString("\n> - " + n)
+	}
+	return b.String()
+}
@@ -0,0 +1,125 @@
+// internal/forge/api.go
+
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// APIError is an unsuccessful response from a forge's API.
+type APIError struct {
+	Forge      string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API %d: %s", e.Forge, e.StatusCode, e.Message)
+}
+
+// doJSON sends in as JSON (when non-nil) and decodes a JSON response into out
+// (when non-nil). Responses outside 2xx become an *APIError.
+func doJSON(ctx context.Context, client *http.Client, forge, method, url string, header map[string]string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "prbuddy-go")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s API request failed: %w", forge, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading %s API response: %w", forge, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{Forge: forge, StatusCode: resp.StatusCode, Message: errorMessage(data, resp.Status)}
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("decoding %s API response: %w", forge, err)
+		}
+	}
+	return nil
+}
+
+// errorMessage extracts the message from an error body: GitHub's message and
+// validation errors, GitLab's message (a string, list or field map) or error.
+func errorMessage(body []byte, status string) string {
+	var parsed struct {
+		Message json.RawMessage `json:"message"`
+		Error   string          `json:"error"`
+		Errors  []struct {
+			Message string `json:"message"`
+			Field   string `json:"field"`
+			Code    string `json:"code"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return status
+	}
+
+	var parts []string
+	if msg := rawMessage(parsed.Message); msg != "" {
+		parts = append(parts, msg)
+	} else if parsed.Error != "" {
+		parts = append(parts, parsed.Error)
+	} else {
+		return status
+	}
+	for _, e := range parsed.Errors {
+		switch {
+		case e.Message != "":
+			parts = append(parts, e.Message)
+		case e.Field != "":
+			parts = append(parts, e.Field+" "+e.Code)
+		}
+	}
+	return strings.Join(parts, ": ")
+}
+
+func rawMessage(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		return strings.Join(list, "; ")
+	}
+	var fields map[string][]string
+	if json.Unmarshal(raw, &fields) == nil {
+		var msgs []string
+		for field, errs := range fields {
+			msgs = append(msgs, field+" "+strings.Join(errs, ", "))
+		}
+		sort.Strings(msgs)
+		return strings.Join(msgs, "; ")
+	}
+	return ""
+}
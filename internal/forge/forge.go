@@ -0,0 +1,100 @@
+// internal/forge/forge.go
+
+package forge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Forge is a code hosting service pull requests (GitLab: merge requests) are opened on.
+type Forge interface {
+	// Name is the service's display name, e.g. "GitHub".
+	Name() string
+	// DefaultBranch returns the repository's default branch.
+	DefaultBranch(ctx context.Context) (string, error)
+	// Labels returns the names of the labels defined in the repository.
+	Labels(ctx context.Context) ([]string, error)
+	// CreatePullRequest opens a pull request. When it is created but assigning it,
+	// requesting reviews or labelling it fails, both the pull request and an error
+	// are returned.
+	CreatePullRequest(ctx context.Context, opts PullRequestOptions) (*PullRequest, error)
+	// FindPullRequest returns the open pull request from branch head, or nil.
+	FindPullRequest(ctx context.Context, head string) (*PullRequest, error)
+	// UpdatePullRequest replaces the title and description of pull request number.
+	UpdatePullRequest(ctx context.Context, number int, title, body string) error
+}
+
+// ErrNoToken is returned when no API token can be found for a forge.
+var ErrNoToken = errors.New("no API token")
+
+// PullRequestOptions describe a pull request to open.
+type PullRequestOptions struct {
+	Title     string
+	Body      string
+	Head      string
+	Base      string
+	Draft     bool
+	Assignees []string
+	Reviewers []string
+	Labels    []string
+}
+
+// PullRequest is a pull request as returned by the API. Title and Body are set
+// by FindPullRequest.
+type PullRequest struct {
+	Number int
+	URL    string
+	Title  string
+	Body   string
+}
+
+// FromRemote returns the client for the forge hosting remote, authenticated
+// with a token from the environment or git's credential helper.
+func FromRemote(remote Remote) (Forge, error) {
+	switch {
+	case IsGitHubHost(remote.Host):
+		envVars := gitHubTokenEnv
+		if remote.Host != "github.com" {
+			envVars = append([]string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}, envVars...)
+		}
+		token := LookupToken(remote.Host, envVars...)
+		if token == "" {
+			return nil, noToken("GitHub", envVars)
+		}
+		return NewGitHub(remote, token), nil
+	case IsGitLabHost(remote.Host):
+		token := LookupToken(remote.Host, gitLabTokenEnv...)
+		if token == "" {
+			return nil, noToken("GitLab", gitLabTokenEnv)
+		}
+		return NewGitLab(remote, token), nil
+	}
+	return nil, fmt.Errorf("%s is neither GitHub nor GitLab", remote.Host)
+}
+
+// FromOrigin returns the client for the forge hosting the origin remote.
+func FromOrigin() (Forge, error) {
+	remote, err := OriginRemote()
+	if err != nil {
+		return nil, err
+	}
+	return FromRemote(remote)
+}
+
+func noToken(forge string, envVars []string) error {
+	return fmt.Errorf("%w for %s: set %s or store one with a git credential helper", ErrNoToken, forge, strings.Join(envVars, ", "))
+}
+
+// SplitList splits a comma-separated flag value, dropping empty entries.
+func SplitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
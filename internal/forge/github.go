@@ -0,0 +1,171 @@
+// internal/forge/github.go
+
+package forge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// gitHubTokenEnv are checked in order before the git credential helper.
+var gitHubTokenEnv = []string{"PRBUDDY_GITHUB_TOKEN", "GITHUB_TOKEN", "GH_TOKEN"}
+
+// GitHub is a minimal client for the GitHub REST API, scoped to one repository.
+type GitHub struct {
+	BaseURL    string // https://api.github.com, or https://<host>/api/v3 for GitHub Enterprise
+	Token      string
+	Remote     Remote
+	HTTPClient *http.Client
+}
+
+var _ Forge = (*GitHub)(nil)
+
+// NewGitHub returns a client for remote authenticated with token.
+func NewGitHub(remote Remote, token string) *GitHub {
+	baseURL := "https://api.github.com"
+	if remote.Host != "github.com" {
+		baseURL = "https://" + remote.Host + "/api/v3"
+	}
+	return &GitHub{
+		BaseURL:    baseURL,
+		Token:      token,
+		Remote:     remote,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// IsGitHubHost reports whether host serves GitHub: github.com, the GH_HOST gh
+// uses for GitHub Enterprise, or any host whose name mentions github.
+func IsGitHubHost(host string) bool {
+	host = strings.ToLower(host)
+	if gh := strings.ToLower(os.Getenv("GH_HOST")); gh != "" && host == gh {
+		return true
+	}
+	return host == "github.com" || strings.Contains(host, "github")
+}
+
+// do sends a request to path (relative to the repository unless it starts with /)
+// and decodes a JSON response into out when out is non-nil.
+func (g *GitHub) do(ctx context.Context, method, path string, in, out interface{}) error {
+	if !strings.HasPrefix(path, "/") {
+		path = fmt.Sprintf("/repos/%s/%s/%s", g.Remote.Owner, g.Remote.Repo, path)
+	}
+	header := map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"X-GitHub-Api-Version": "2022-11-28",
+	}
+	if g.Token != "" {
+		header["Authorization"] = "Bearer " + g.Token
+	}
+	return doJSON(ctx, g.HTTPClient, "GitHub", method, strings.TrimRight(g.BaseURL, "/")+path, header, in, out)
+}
+
+// Name implements Forge.
+func (g *GitHub) Name() string { return "GitHub" }
+
+// DefaultBranch returns the repository's default branch.
+func (g *GitHub) DefaultBranch(ctx context.Context) (string, error) {
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := g.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s", g.Remote.Owner, g.Remote.Repo), nil, &repo); err != nil {
+		return "", err
+	}
+	if repo.DefaultBranch == "" {
+		return "", fmt.Errorf("GitHub did not report a default branch for %s", g.Remote.FullName())
+	}
+	return repo.DefaultBranch, nil
+}
+
+// Labels returns the names of the labels defined in the repository.
+func (g *GitHub) Labels(ctx context.Context) ([]string, error) {
+	const perPage = 100
+	var names []string
+	for page := 1; page <= 10; page++ {
+		var labels []struct {
+			Name string `json:"name"`
+		}
+		if err := g.do(ctx, http.MethodGet, fmt.Sprintf("labels?per_page=%d&page=%d", perPage, page), nil, &labels); err != nil {
+			return nil, err
+		}
+		for _, l := range labels {
+			names = append(names, l.Name)
+		}
+		if len(labels) < perPage {
+			break
+		}
+	}
+	return names, nil
+}
+
+// CreatePullRequest opens a pull request, then assigns it, requests reviews and
+// adds labels. The pull request exists once the first request succeeds, so
+// failures of the follow-up calls are returned together with it.
+func (g *GitHub) CreatePullRequest(ctx context.Context, opts PullRequestOptions) (*PullRequest, error) {
+	payload := map[string]interface{}{
+		"title": opts.Title,
+		"body":  opts.Body,
+		"head":  opts.Head,
+		"base":  opts.Base,
+		"draft": opts.Draft,
+	}
+	var pr struct {
+		Number int    `json:"number"`
+		URL    string `json:"html_url"`
+	}
+	if err := g.do(ctx, http.MethodPost, "pulls", payload, &pr); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	if len(opts.Assignees) > 0 {
+		if err := g.do(ctx, http.MethodPost, fmt.Sprintf("issues/%d/assignees", pr.Number),
+			map[string][]string{"assignees": opts.Assignees}, nil); err != nil {
+			errs = append(errs, fmt.Errorf("assignees: %w", err))
+		}
+	}
+	if len(opts.Reviewers) > 0 {
+		if err := g.do(ctx, http.MethodPost, fmt.Sprintf("pulls/%d/requested_reviewers", pr.Number),
+			map[string][]string{"reviewers": opts.Reviewers}, nil); err != nil {
+			errs = append(errs, fmt.Errorf("reviewers: %w", err))
+		}
+	}
+	if len(opts.Labels) > 0 {
+		if err := g.do(ctx, http.MethodPost, fmt.Sprintf("issues/%d/labels", pr.Number),
+			map[string][]string{"labels": opts.Labels}, nil); err != nil {
+			errs = append(errs, fmt.Errorf("labels: %w", err))
+		}
+	}
+	return &PullRequest{Number: pr.Number, URL: pr.URL}, errors.Join(errs...)
+}
+
+// FindPullRequest returns the open pull request from head in the same repository.
+func (g *GitHub) FindPullRequest(ctx context.Context, head string) (*PullRequest, error) {
+	var prs []struct {
+		Number int    `json:"number"`
+		URL    string `json:"html_url"`
+		Title  string `json:"title"`
+		Body   string `json:"body"`
+	}
+	query := "pulls?state=open&head=" + url.QueryEscape(g.Remote.Owner+":"+head)
+	if err := g.do(ctx, http.MethodGet, query, nil, &prs); err != nil {
+		return nil, err
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	pr := prs[0]
+	return &PullRequest{Number: pr.Number, URL: pr.URL, Title: pr.Title, Body: pr.Body}, nil
+}
+
+// UpdatePullRequest replaces the title and body of pull request number.
+func (g *GitHub) UpdatePullRequest(ctx context.Context, number int, title, body string) error {
+	return g.do(ctx, http.MethodPatch, fmt.Sprintf("pulls/%d", number),
+		map[string]string{"title": title, "body": body}, nil)
+}
@@ -0,0 +1,215 @@
+// internal/forge/gitlab.go
+
+package forge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// gitLabTokenEnv are checked in order before the git credential helper.
+var gitLabTokenEnv = []string{"PRBUDDY_GITLAB_TOKEN", "GITLAB_TOKEN", "GITLAB_PRIVATE_TOKEN"}
+
+// GitLab is a minimal client for the GitLab REST API (v4), scoped to one project.
+type GitLab struct {
+	BaseURL    string // https://<host>/api/v4
+	Token      string
+	Remote     Remote
+	HTTPClient *http.Client
+}
+
+var _ Forge = (*GitLab)(nil)
+
+// NewGitLab returns a client for remote authenticated with token.
+func NewGitLab(remote Remote, token string) *GitLab {
+	return &GitLab{
+		BaseURL:    "https://" + remote.Host + "/api/v4",
+		Token:      token,
+		Remote:     remote,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// IsGitLabHost reports whether host serves GitLab: gitlab.com, the GITLAB_HOST
+// glab uses for self-managed instances, or any host whose name mentions gitlab.
+func IsGitLabHost(host string) bool {
+	host = strings.ToLower(host)
+	if gl := strings.ToLower(os.Getenv("GITLAB_HOST")); gl != "" {
+		if u, err := url.Parse(gl); err == nil && u.Host != "" {
+			gl = u.Hostname()
+		}
+		if host == gl {
+			return true
+		}
+	}
+	return strings.Contains(host, "gitlab")
+}
+
+// Name implements Forge.
+func (g *GitLab) Name() string { return "GitLab" }
+
+// do sends a request to path (relative to the project unless it starts with /)
+// and decodes a JSON response into out when out is non-nil.
+func (g *GitLab) do(ctx context.Context, method, path string, in, out interface{}) error {
+	if !strings.HasPrefix(path, "/") {
+		path = "/projects/" + url.PathEscape(g.Remote.FullName()) + "/" + path
+	}
+	header := map[string]string{"Accept": "application/json"}
+	if g.Token != "" {
+		header["Authorization"] = "Bearer " + g.Token
+	}
+	return doJSON(ctx, g.HTTPClient, "GitLab", method, strings.TrimRight(g.BaseURL, "/")+path, header, in, out)
+}
+
+// DefaultBranch returns the project's default branch.
+func (g *GitLab) DefaultBranch(ctx context.Context) (string, error) {
+	var project struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := g.do(ctx, http.MethodGet, "/projects/"+url.PathEscape(g.Remote.FullName()), nil, &project); err != nil {
+		return "", err
+	}
+	if project.DefaultBranch == "" {
+		return "", fmt.Errorf("GitLab did not report a default branch for %s", g.Remote.FullName())
+	}
+	return project.DefaultBranch, nil
+}
+
+// Labels returns the names of the labels available in the project.
+func (g *GitLab) Labels(ctx context.Context) ([]string, error) {
+	const perPage = 100
+	var names []string
+	for page := 1; page <= 10; page++ {
+		var labels []struct {
+			Name string `json:"name"`
+		}
+		if err := g.do(ctx, http.MethodGet, fmt.Sprintf("labels?per_page=%d&page=%d", perPage, page), nil, &labels); err != nil {
+			return nil, err
+		}
+		for _, l := range labels {
+			names = append(names, l.Name)
+		}
+		if len(labels) < perPage {
+			break
+		}
+	}
+	return names, nil
+}
+
+// userIDs resolves usernames to the numeric IDs merge requests refer to users by.
+// Unknown users are left out and reported in the error.
+func (g *GitLab) userIDs(ctx context.Context, usernames []string) ([]int, error) {
+	var ids []int
+	var errs []error
+	for _, name := range usernames {
+		var users []struct {
+			ID int `json:"id"`
+		}
+		err := g.do(ctx, http.MethodGet, "/users?username="+url.QueryEscape(strings.TrimPrefix(name, "@")), nil, &users)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		case len(users) == 0:
+			errs = append(errs, fmt.Errorf("unknown user %q", name))
+		default:
+			ids = append(ids, users[0].ID)
+		}
+	}
+	return ids, errors.Join(errs...)
+}
+
+// draftPrefixes mark a merge request as a draft; GitLab has no separate flag.
+var draftPrefixes = []string{"Draft:", "[Draft]", "(Draft)", "WIP:", "[WIP]"}
+
+// CreatePullRequest opens a merge request from opts.Head into opts.Base. Assignees
+// and reviewers are set in the same request, so users that can't be resolved are
+// left out and reported in the error returned alongside the merge request.
+func (g *GitLab) CreatePullRequest(ctx context.Context, opts PullRequestOptions) (*PullRequest, error) {
+	var errs []error
+	assigneeIDs, err := g.userIDs(ctx, opts.Assignees)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("assignees: %w", err))
+	}
+	reviewerIDs, err := g.userIDs(ctx, opts.Reviewers)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("reviewers: %w", err))
+	}
+
+	title := opts.Title
+	if opts.Draft && !isDraftTitle(title) {
+		title = "Draft: " + title
+	}
+	payload := map[string]interface{}{
+		"source_branch": opts.Head,
+		"target_branch": opts.Base,
+		"title":         title,
+		"description":   opts.Body,
+	}
+	if len(assigneeIDs) > 0 {
+		payload["assignee_ids"] = assigneeIDs
+	}
+	if len(reviewerIDs) > 0 {
+		payload["reviewer_ids"] = reviewerIDs
+	}
+	if len(opts.Labels) > 0 {
+		payload["labels"] = strings.Join(opts.Labels, ",")
+	}
+
+	var mr gitLabMergeRequest
+	if err := g.do(ctx, http.MethodPost, "merge_requests", payload, &mr); err != nil {
+		return nil, err
+	}
+	return mr.pullRequest(), errors.Join(errs...)
+}
+
+// FindPullRequest returns the open merge request from branch head.
+func (g *GitLab) FindPullRequest(ctx context.Context, head string) (*PullRequest, error) {
+	var mrs []gitLabMergeRequest
+	if err := g.do(ctx, http.MethodGet, "merge_requests?state=opened&source_branch="+url.QueryEscape(head), nil, &mrs); err != nil {
+		return nil, err
+	}
+	if len(mrs) == 0 {
+		return nil, nil
+	}
+	return mrs[0].pullRequest(), nil
+}
+
+// UpdatePullRequest replaces the title and description of merge request number,
+// keeping it a draft if it is one.
+func (g *GitLab) UpdatePullRequest(ctx context.Context, number int, title, body string) error {
+	var current gitLabMergeRequest
+	if err := g.do(ctx, http.MethodGet, fmt.Sprintf("merge_requests/%d", number), nil, &current); err != nil {
+		return err
+	}
+	if isDraftTitle(current.Title) && !isDraftTitle(title) {
+		title = "Draft: " + title
+	}
+	return g.do(ctx, http.MethodPut, fmt.Sprintf("merge_requests/%d", number),
+		map[string]string{"title": title, "description": body}, nil)
+}
+
+type gitLabMergeRequest struct {
+	IID         int    `json:"iid"`
+	WebURL      string `json:"web_url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+func (mr gitLabMergeRequest) pullRequest() *PullRequest {
+	return &PullRequest{Number: mr.IID, URL: mr.WebURL, Title: mr.Title, Body: mr.Description}
+}
+
+func isDraftTitle(title string) bool {
+	for _, p := range draftPrefixes {
+		if len(title) >= len(p) && strings.EqualFold(title[:len(p)], p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+// internal/forge/remote.go
+
+package forge
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// Remote identifies a repository on a forge, as parsed from a git remote URL.
+type Remote struct {
+	Host  string // e.g. github.com, without port
+	Owner string // user or organisation (may contain slashes on forges with nested groups)
+	Repo  string // repository name without .git
+}
+
+// FullName returns owner/repo.
+func (r Remote) FullName() string {
+	return r.Owner + "/" + r.Repo
+}
+
+// ParseRemoteURL understands the URL forms git accepts for a remote:
+// https://host/owner/repo(.git), ssh://git@host[:port]/owner/repo and the
+// scp-like git@host:owner/repo.
+func ParseRemoteURL(raw string) (Remote, error) {
+	raw = strings.TrimSpace(raw)
+	var host, path string
+
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return Remote{}, fmt.Errorf("invalid remote URL %q: %w", raw, err)
+		}
+		host, path = u.Hostname(), u.Path
+	} else if at := strings.Index(raw, ":"); at > 0 && !strings.Contains(raw[:at], "/") {
+		// scp-like syntax: [user@]host:path
+		host, path = raw[:at], raw[at+1:]
+		if i := strings.LastIndex(host, "@"); i >= 0 {
+			host = host[i+1:]
+		}
+	} else {
+		return Remote{}, fmt.Errorf("remote %q is not a URL of a hosted repository", raw)
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	i := strings.LastIndex(path, "/")
+	if host == "" || i <= 0 || i == len(path)-1 {
+		return Remote{}, fmt.Errorf("remote %q does not name an owner/repository", raw)
+	}
+	return Remote{Host: strings.ToLower(host), Owner: path[:i], Repo: path[i+1:]}, nil
+}
+
+// OriginRemote parses the URL of the origin remote of the current repository.
+func OriginRemote() (Remote, error) {
+	raw, err := utils.ExecGit("remote", "get-url", "origin")
+	if err != nil {
+		return Remote{}, fmt.Errorf("no origin remote: %w", err)
+	}
+	return ParseRemoteURL(raw)
+}
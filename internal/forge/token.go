@@ -0,0 +1,50 @@
+// internal/forge/token.go
+
+package forge
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// credentialTimeout bounds 'git credential fill', which may consult a keychain.
+const credentialTimeout = 5 * time.Second
+
+// LookupToken returns an API token for host from the first of envVars that is
+// set, falling back to the password git's credential helper stores for
+// https://host. It returns "" when neither has one.
+func LookupToken(host string, envVars ...string) string {
+	for _, name := range envVars {
+		if token := strings.TrimSpace(os.Getenv(name)); token != "" {
+			return token
+		}
+	}
+	return credentialHelperPassword(host)
+}
+
+// credentialHelperPassword asks the configured credential helpers, never prompting:
+// a missing credential must not leave pr create waiting on the terminal.
+func credentialHelperPassword(host string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), credentialTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "-c", "credential.interactive=false", "credential", "fill")
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=" + host + "\n\n")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=", "GCM_INTERACTIVE=never")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, "password="); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+// internal/hooks/prepare_commit_msg_hook.go
+
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// prepareCommitMsgMarker identifies PRBuddy's line in a prepare-commit-msg hook.
+const prepareCommitMsgMarker = "prbuddy-go commit-msg"
+
+// prepareCommitMsgContent fills in the commit message from the staged changes. The
+// trailing "|| true" keeps a failing or missing prbuddy-go from aborting the commit.
+const prepareCommitMsgContent = `# Suggest a commit message for the staged changes (never blocks the commit)
+prbuddy-go commit-msg "$1" "$2" "$3" || true`
+
+func prepareCommitMsgPath() (string, error) {
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(repoPath, ".git", "hooks", "prepare-commit-msg"), nil
+}
+
+// InstallPrepareCommitMsgHook adds the commit message suggestion to the
+// prepare-commit-msg hook, appending to an existing hook rather than replacing it.
+func InstallPrepareCommitMsgHook() error {
+	hookPath, err := prepareCommitMsgPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	existing, err := os.ReadFile(hookPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read existing prepare-commit-msg hook: %w", err)
+	}
+	if strings.Contains(string(existing), prepareCommitMsgMarker) {
+		fmt.Println(utils.Green("[PRBuddy-Go] prepare-commit-msg hook already contains PRBuddy logic. Skipping reinstallation."))
+		return nil
+	}
+
+	content := "#!/bin/sh\n# Added by PRBuddy-Go\n" + prepareCommitMsgContent + "\n"
+	if len(existing) > 0 {
+		content = strings.TrimRight(string(existing), "\n") + "\n\n# Added by PRBuddy-Go\n" + prepareCommitMsgContent + "\n"
+	}
+	if err := os.WriteFile(hookPath, []byte(content), 0755); err != nil {
+		return fmt.Errorf("failed to write prepare-commit-msg hook: %w", err)
+	}
+	fmt.Printf(utils.Cyan("[PRBuddy-Go] prepare-commit-msg hook installed at %s\n"), hookPath)
+	return nil
+}
+
+// RemovePrepareCommitMsgHook takes PRBuddy's lines out of the prepare-commit-msg hook,
+// deleting the hook when nothing else is left in it.
+func RemovePrepareCommitMsgHook() error {
+	hookPath, err := prepareCommitMsgPath()
+	if err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(hookPath)
+	if os.IsNotExist(err) || !strings.Contains(string(existing), prepareCommitMsgMarker) {
+		fmt.Printf("[PRBuddy-Go] No PRBuddy prepare-commit-msg hook found at %s\n", hookPath)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read prepare-commit-msg hook: %w", err)
+	}
+
+	remaining := strings.Replace(string(existing), "# Added by PRBuddy-Go\n"+prepareCommitMsgContent, "", 1)
+	if strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(remaining), "#!/bin/sh")) == "" {
+		if err := os.Remove(hookPath); err != nil {
+			return fmt.Errorf("failed to remove prepare-commit-msg hook: %w", err)
+		}
+	} else if err := os.WriteFile(hookPath, []byte(strings.TrimRight(remaining, "\n")+"\n"), 0755); err != nil {
+		return fmt.Errorf("failed to update prepare-commit-msg hook: %w", err)
+	}
+
+	fmt.Printf("[PRBuddy-Go] prepare-commit-msg hook removed from %s\n", hookPath)
+	return nil
+}
@@ -0,0 +1,364 @@
+// internal/llm/api.go
+
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// APIVersion prefixes every route of the versioned API.
+const APIVersion = "v1"
+
+// Version is reported by /v1/info; release builds set it with
+// -ldflags "-X github.com/soyuz43/prbuddy-go/internal/llm.Version=v1.2.3".
+var Version = "dev"
+
+// serverStartedAt is reset by StartServer; /v1/info reports the uptime from it.
+var serverStartedAt = time.Now()
+
+// apiRoute is an endpoint of the versioned API. The OpenAPI document is generated
+// from the route table, so Request and Response must be the types the handler uses.
+type apiRoute struct {
+	Method  string
+	Path    string // ServeMux pattern, e.g. /v1/conversations/{id}
+	Summary string
+	// Request is the JSON body, or for GET and DELETE the query parameters; nil for none
+	Request  any
+	Response any
+	// Stream marks endpoints answering with Server-Sent Events (see SSEHandler)
+	Stream bool
+	// Produces is a content type offered besides JSON, e.g. for downloads
+	Produces string
+	Handler  http.HandlerFunc
+}
+
+// Response types of the versioned API
+type (
+	HealthResponse struct {
+		Status string `json:"status"`
+	}
+
+	InfoResponse struct {
+		Version           string    `json:"version"`
+		APIVersion        string    `json:"apiVersion"`
+		RepoPath          string    `json:"repoPath,omitempty"`
+		Provider          string    `json:"provider"`
+		ActiveModel       string    `json:"activeModel,omitempty"`
+		StartedAt         time.Time `json:"startedAt"`
+		UptimeSeconds     int64     `json:"uptimeSeconds"`
+		OpenConversations int       `json:"openConversations"`
+	}
+
+	ChatResponse struct {
+		ConversationID string `json:"conversationId"`
+		Response       string `json:"response"`
+	}
+
+	SummaryResponse struct {
+		Summary string `json:"summary"`
+	}
+
+	StatusResponse struct {
+		Status string `json:"status"`
+	}
+
+	DraftLoadResponse struct {
+		Messages []contextpkg.Message `json:"messages"`
+	}
+
+	ModelResponse struct {
+		ActiveModel string `json:"activeModel"`
+	}
+)
+
+func apiRoutes() []apiRoute {
+	routes := []apiRoute{
+		{
+			Method: http.MethodGet, Path: "/v1/health", Summary: "Report that the server is up",
+			Response: HealthResponse{},
+			Handler: apiHandler(func(_ *http.Request, _ struct{}) (HealthResponse, error) {
+				return HealthResponse{Status: "ok"}, nil
+			}),
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/info", Summary: "Describe the server, repository and model",
+			Response: InfoResponse{},
+			Handler:  apiHandler(infoHandler),
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/openapi.json", Summary: "This API as an OpenAPI 3 document",
+			Response: map[string]any{},
+			Handler: apiHandler(func(_ *http.Request, _ struct{}) (map[string]any, error) {
+				return openAPIDocument(apiRoutes()), nil
+			}),
+		},
+		{
+			Method: http.MethodPost, Path: "/v1/quickassist", Summary: "Ask in a persistent conversation",
+			Request: QuickAssistRequest{}, Response: ChatResponse{},
+			Handler: apiHandler(func(r *http.Request, req QuickAssistRequest) (ChatResponse, error) {
+				id, response, err := StreamQuickAssist(r.Context(), req.ConversationID, req.Input, nil)
+				return ChatResponse{ConversationID: id, Response: response}, err
+			}),
+		},
+		{
+			Method: http.MethodPost, Path: "/v1/quickassist/stream", Summary: "Ask in a persistent conversation, streaming the answer",
+			Request: QuickAssistRequest{}, Stream: true,
+			Handler: quickAssistStreamHandler(),
+		},
+		{
+			Method: http.MethodPost, Path: "/v1/quickassist/clear", Summary: "Forget a conversation",
+			Request: ClearRequest{}, Response: StatusResponse{},
+			Handler: apiHandler(func(_ *http.Request, req ClearRequest) (StatusResponse, error) {
+				if req.ConversationID == "" {
+					return StatusResponse{}, invalidRequest("conversationId is required")
+				}
+				contextpkg.ConversationManagerInstance.RemoveConversation(req.ConversationID)
+				return StatusResponse{Status: "cleared"}, nil
+			}),
+		},
+		{
+			Method: http.MethodPost, Path: "/v1/dce", Summary: "Ask with the Dynamic Context Engine",
+			Request: DCERequest{}, Response: ChatResponse{},
+			Handler: apiHandler(func(r *http.Request, req DCERequest) (ChatResponse, error) {
+				id, response, err := StreamDCERequest(r.Context(), req.ConversationID, req.Input, nil)
+				return ChatResponse{ConversationID: id, Response: response}, err
+			}),
+		},
+		{
+			Method: http.MethodPost, Path: "/v1/dce/stream", Summary: "Ask with the Dynamic Context Engine, streaming the answer",
+			Request: DCERequest{}, Stream: true,
+			Handler: dceStreamHandler(),
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/what", Summary: "Summarize the uncommitted changes",
+			Response: SummaryResponse{},
+			Handler: apiHandler(func(r *http.Request, _ struct{}) (SummaryResponse, error) {
+				summary, err := GenerateWhatSummary(r.Context())
+				return SummaryResponse{Summary: summary}, err
+			}),
+		},
+		{
+			Method: http.MethodPost, Path: "/v1/what/stream", Summary: "Summarize the uncommitted changes, streaming the summary",
+			Stream:  true,
+			Handler: whatStreamHandler(),
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/drafts", Summary: "Load the saved conversation of a draft",
+			Request: DraftLoadRequest{}, Response: DraftLoadResponse{},
+			Handler: apiHandler(func(_ *http.Request, req DraftLoadRequest) (DraftLoadResponse, error) {
+				if req.Branch == "" || req.Commit == "" {
+					return DraftLoadResponse{}, invalidRequest("branch and commit are required")
+				}
+				messages, err := LoadDraftContext(req.Branch, req.Commit)
+				return DraftLoadResponse{Messages: messages}, err
+			}),
+		},
+		{
+			Method: http.MethodPost, Path: "/v1/drafts", Summary: "Save the conversation of a draft",
+			Request: DraftSaveRequest{}, Response: StatusResponse{},
+			Handler: apiHandler(func(_ *http.Request, req DraftSaveRequest) (StatusResponse, error) {
+				if req.Branch == "" || req.Commit == "" {
+					return StatusResponse{}, invalidRequest("branch and commit are required")
+				}
+				if len(req.Messages) == 0 {
+					return StatusResponse{}, invalidRequest("messages are required")
+				}
+				if err := SaveDraftContext(req.Branch, req.Commit, req.Messages); err != nil {
+					return StatusResponse{}, err
+				}
+				return StatusResponse{Status: "saved"}, nil
+			}),
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/models", Summary: "List the models of the LLM server",
+			Response: []map[string]any{},
+			Handler: apiHandler(func(r *http.Request, _ struct{}) ([]map[string]any, error) {
+				return fetchModels(r.Context(), GetLLMEndpoint())
+			}),
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/model", Summary: "Show the active model",
+			Response: ModelResponse{},
+			Handler: apiHandler(func(_ *http.Request, _ struct{}) (ModelResponse, error) {
+				return ModelResponse{ActiveModel: contextpkg.GetActiveModel()}, nil
+			}),
+		},
+		{
+			Method: http.MethodPut, Path: "/v1/model", Summary: "Switch the active model",
+			Request: ModelRequest{}, Response: ModelResponse{},
+			Handler: apiHandler(func(_ *http.Request, req ModelRequest) (ModelResponse, error) {
+				if req.Model == "" {
+					return ModelResponse{}, invalidRequest("missing 'model' field")
+				}
+				contextpkg.SetActiveModel(req.Model)
+				return ModelResponse{ActiveModel: contextpkg.GetActiveModel()}, nil
+			}),
+		},
+	}
+	return append(routes, conversationRoutes()...)
+}
+
+func infoHandler(_ *http.Request, _ struct{}) (InfoResponse, error) {
+	info := InfoResponse{
+		Version:           version(),
+		APIVersion:        APIVersion,
+		Provider:          GetLLMProvider(),
+		ActiveModel:       contextpkg.GetActiveModel(),
+		StartedAt:         serverStartedAt.UTC(),
+		UptimeSeconds:     int64(time.Since(serverStartedAt).Seconds()),
+		OpenConversations: contextpkg.ConversationManagerInstance.Count(),
+	}
+	// Not fatal: the server may run outside a repository
+	if repoPath, err := utils.GetRepoPath(); err == nil {
+		info.RepoPath = repoPath
+	}
+	return info, nil
+}
+
+// version is Version, or the module version of a `go install`ed binary.
+func version() string {
+	if Version == "dev" {
+		if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			return bi.Main.Version
+		}
+	}
+	return Version
+}
+
+// registerAPI adds the versioned routes to router. Each path gets one handler
+// that dispatches on the method, so every error, 404 and 405 included, has the
+// structured form.
+func registerAPI(router *http.ServeMux) {
+	byPath := make(map[string]map[string]http.HandlerFunc)
+	for _, route := range apiRoutes() {
+		if byPath[route.Path] == nil {
+			byPath[route.Path] = make(map[string]http.HandlerFunc)
+		}
+		byPath[route.Path][route.Method] = route.Handler
+	}
+
+	for path, methods := range byPath {
+		allowed := make([]string, 0, len(methods))
+		for method := range methods {
+			allowed = append(allowed, method)
+		}
+		sort.Strings(allowed)
+
+		router.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			handler, ok := methods[r.Method]
+			if !ok && r.Method == http.MethodHead {
+				handler, ok = methods[http.MethodGet]
+			}
+			if !ok {
+				w.Header().Set("Allow", strings.Join(allowed, ", "))
+				writeError(w, methodNotAllowed(r.Method))
+				return
+			}
+			handler(w, r)
+		})
+	}
+
+	router.HandleFunc("/"+APIVersion+"/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, newAPIError(http.StatusNotFound, CodeNotFound, "No route for %s %s", r.Method, r.URL.Path))
+	})
+}
+
+// apiHandler adapts logic to a versioned route: the request is decoded from the
+// JSON body, or from the query string for GET and DELETE, and the response is
+// encoded as JSON. Method checks are left to registerAPI.
+func apiHandler[Req, Resp any](logic func(*http.Request, Req) (Resp, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodDelete:
+			if err := decodeQuery(r.URL.Query(), &req); err != nil {
+				writeError(w, invalidRequest("Invalid query: %v", err))
+				return
+			}
+		default:
+			// An empty body is an empty request; the handler validates it
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+				writeError(w, invalidRequest("Invalid request format: %v", err))
+				return
+			}
+		}
+
+		response, err := logic(r, req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		jsonResponse, err := utils.MarshalJSON(response)
+		if err != nil {
+			writeError(w, newAPIError(http.StatusInternalServerError, CodeInternal, "Failed to marshal response"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(jsonResponse))
+	}
+}
+
+// decodeQuery sets the string, bool and integer fields of the struct dst points
+// to from the query parameters named by their json tags.
+func decodeQuery(values url.Values, dst any) error {
+	v := reflect.ValueOf(dst).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < v.NumField(); i++ {
+		name := jsonName(v.Type().Field(i))
+		raw := values.Get(name)
+		if name == "" || raw == "" {
+			continue
+		}
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(raw)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			field.SetBool(b)
+		case reflect.Int, reflect.Int64:
+			n, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			field.SetInt(n)
+		}
+	}
+	return nil
+}
+
+// jsonName is the name field is encoded under, "" when it isn't.
+func jsonName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,117 @@
+// internal/llm/auth.go
+
+package llm
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// allowedOriginsEnv lists the browser origins (e.g. "vscode-webview://*") allowed
+// to call the server, comma-separated; a trailing "*" matches any suffix.
+const allowedOriginsEnv = "PRBUDDY_ALLOWED_ORIGINS"
+
+// NewSessionToken returns a random bearer token for one server run.
+func NewSessionToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("token generation failed: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// AuthConfig is what RequireAuth checks requests against.
+type AuthConfig struct {
+	// Token must be sent as "Authorization: Bearer <token>"
+	Token string
+	// Port the server listens on; the Host header must name it on a loopback host
+	Port int
+	// AllowedOrigins may call from a browser context; see allowedOriginsEnv
+	AllowedOrigins []string
+}
+
+// AllowedOriginsFromEnv reads the origin allowlist from PRBUDDY_ALLOWED_ORIGINS.
+func AllowedOriginsFromEnv() []string {
+	var origins []string
+	for _, o := range strings.Split(os.Getenv(allowedOriginsEnv), ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			origins = append(origins, o)
+		}
+	}
+	return origins
+}
+
+// RequireAuth rejects requests whose Host isn't the loopback address the server
+// listens on (DNS rebinding), browser requests without an allowlisted Origin, and
+// requests without the session token. Preflight requests from allowed origins are
+// answered here, as browsers send them without credentials.
+func RequireAuth(cfg AuthConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopbackHost(r.Host, cfg.Port) {
+			writeError(w, newAPIError(http.StatusMisdirectedRequest, CodeMisdirected, "Invalid Host header %q", r.Host))
+			return
+		}
+
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if !originAllowed(origin, cfg.AllowedOrigins) {
+				writeError(w, newAPIError(http.StatusForbidden, CodeForbidden, "Origin %q not allowed", origin))
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+			if r.Method == http.MethodOptions {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		} else if site := r.Header.Get("Sec-Fetch-Site"); site != "" && site != "none" {
+			// Sent by browsers only; a page that hides its Origin gets no access either
+			writeError(w, newAPIError(http.StatusForbidden, CodeForbidden, "Browser requests need an allowed Origin"))
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(cfg.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="prbuddy-go"`)
+			writeError(w, newAPIError(http.StatusUnauthorized, CodeUnauthorized, "Missing or invalid bearer token"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost reports whether host (a Host header) is localhost or a loopback
+// IP with the server's port.
+func isLoopbackHost(host string, port int) bool {
+	name, p, err := net.SplitHostPort(host)
+	if err != nil || p != strconv.Itoa(port) {
+		return false
+	}
+	if strings.EqualFold(name, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(name)
+	return ip != nil && ip.IsLoopback()
+}
+
+func originAllowed(origin string, allowed []string) bool {
+	for _, a := range allowed {
+		if prefix, ok := strings.CutSuffix(a, "*"); ok {
+			if strings.HasPrefix(origin, prefix) {
+				return true
+			}
+		} else if origin == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+// internal/llm/branch_draft.go
+
+package llm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// BranchDraftInput collects every commit since HEAD diverged from base together with
+// the cumulative merge-base...HEAD diff, so a multi-commit PR is drafted as a whole.
+func BranchDraftInput(base string) (DraftInput, error) {
+	mergeBase, err := utils.GetMergeBase(base)
+	if err != nil {
+		return DraftInput{}, errors.Wrap(err, "failed to find merge base")
+	}
+
+	// %x1e separates commits, %x00 separates the short hash from the message
+	log, err := utils.ExecGit("log", "--reverse", "--format=%h%x00%B%x1e", mergeBase+"..HEAD")
+	if err != nil {
+		return DraftInput{}, errors.Wrap(err, "failed to get branch commit messages")
+	}
+
+	var b strings.Builder
+	var messages []string
+	commits := 0
+	for _, entry := range strings.Split(log, "\x1e") {
+		hash, message, ok := strings.Cut(strings.TrimSpace(entry), "\x00")
+		if !ok {
+			continue
+		}
+		commits++
+		messages = append(messages, strings.TrimSpace(message))
+		b.WriteString(fmt.Sprintf("### %s %s\n\n", hash, strings.TrimSpace(message)))
+	}
+	if commits == 0 {
+		return DraftInput{}, fmt.Errorf("no commits on this branch since %s", base)
+	}
+
+	diff, err := utils.ExecGit("diff", mergeBase, "HEAD")
+	if err != nil {
+		return DraftInput{}, errors.Wrap(err, "failed to get branch diff")
+	}
+
+	header := fmt.Sprintf("This pull request contains %d commit(s) since it branched from %s:\n\n", commits, base)
+	return DraftInput{
+		CommitMessage: header + strings.TrimSpace(b.String()),
+		Diff:          diff,
+		Messages:      messages,
+		Base:          base,
+		MergeBase:     mergeBase,
+		Commits:       commits,
+	}, nil
+}
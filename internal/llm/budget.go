@@ -0,0 +1,245 @@
+// internal/llm/budget.go
+
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+)
+
+//------------------------------------------------------------------------------
+// MODEL CONTEXT LENGTH
+//------------------------------------------------------------------------------
+
+const (
+	// fallbackContextLength is used when the server can't tell us the model's window.
+	fallbackContextLength = 8192
+	// defaultMaxContextLength caps auto-detected windows: Ollama allocates memory
+	// for the full num_ctx, so a 128k window on a laptop is rarely what anyone wants.
+	defaultMaxContextLength = 32768
+)
+
+var (
+	contextLengthMu    sync.Mutex
+	contextLengthCache = make(map[string]int)
+)
+
+// ModelContextLength returns the token window to use for model.
+// PRBUDDY_LLM_NUM_CTX wins outright; otherwise the server is asked (Ollama /api/show,
+// or the OpenAI-compatible /v1/models metadata) and the result capped by
+// PRBUDDY_LLM_MAX_CTX. Lookups are cached per model.
+func ModelContextLength(ctx context.Context, model string) int {
+	if n := envInt("PRBUDDY_LLM_NUM_CTX"); n > 0 {
+		return n
+	}
+
+	contextLengthMu.Lock()
+	cached, ok := contextLengthCache[model]
+	contextLengthMu.Unlock()
+	if ok {
+		return cached
+	}
+
+	var detected int
+	if GetLLMProvider() == ProviderOpenAI {
+		detected = fetchOpenAIContextLength(ctx, model)
+	} else {
+		detected = fetchOllamaContextLength(ctx, GetLLMEndpoint(), model)
+	}
+
+	// Don't cache the fallback: the server may simply not be up yet
+	if detected <= 0 {
+		return fallbackContextLength
+	}
+
+	length := detected
+	maxLen := envInt("PRBUDDY_LLM_MAX_CTX")
+	if maxLen <= 0 {
+		maxLen = defaultMaxContextLength
+	}
+	if length > maxLen {
+		length = maxLen
+	}
+
+	contextLengthMu.Lock()
+	contextLengthCache[model] = length
+	contextLengthMu.Unlock()
+	return length
+}
+
+var numCtxParam = regexp.MustCompile(`(?m)^num_ctx\s+(\d+)`)
+
+// fetchOllamaContextLength reads "<arch>.context_length" (or a Modelfile num_ctx) from /api/show.
+func fetchOllamaContextLength(ctx context.Context, endpoint, model string) int {
+	payload, _ := json.Marshal(map[string]string{"model": model})
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint+"/api/show", bytes.NewReader(payload))
+	if err != nil {
+		return 0
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := discoveryClient.Do(req)
+	if err != nil {
+		logrus.Debugf("Context length lookup failed for %s: %v", model, err)
+		return 0
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0
+	}
+
+	var show struct {
+		Parameters string                 `json:"parameters"`
+		ModelInfo  map[string]interface{} `json:"model_info"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&show); err != nil {
+		return 0
+	}
+
+	// An explicit num_ctx in the Modelfile reflects what the author tuned the model for
+	if m := numCtxParam.FindStringSubmatch(show.Parameters); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil && n > 0 {
+			return n
+		}
+	}
+	for key, value := range show.ModelInfo {
+		if strings.HasSuffix(key, ".context_length") {
+			if n, ok := value.(float64); ok && n > 0 {
+				return int(n)
+			}
+		}
+	}
+	return 0
+}
+
+// fetchOpenAIContextLength reads vLLM's max_model_len or llama.cpp's meta.n_ctx_train from /v1/models.
+func fetchOpenAIContextLength(ctx context.Context, model string) int {
+	models, err := fetchOpenAIModels(ctx, GetLLMEndpoint())
+	if err != nil {
+		return 0
+	}
+	for _, m := range models {
+		if name, _ := m["name"].(string); name != model && model != "" {
+			continue
+		}
+		if n, ok := m["max_model_len"].(float64); ok && n > 0 {
+			return int(n)
+		}
+		if meta, ok := m["meta"].(map[string]interface{}); ok {
+			if n, ok := meta["n_ctx_train"].(float64); ok && n > 0 {
+				return int(n)
+			}
+		}
+	}
+	return 0
+}
+
+func envInt(name string) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		logrus.Warnf("Ignoring invalid %s=%q", name, raw)
+		return 0
+	}
+	return n
+}
+
+//------------------------------------------------------------------------------
+// BUDGETS
+//------------------------------------------------------------------------------
+
+// BudgetForModel returns the prompt budget for model, reserving a quarter of the
+// window (between 512 and 4096 tokens) for the response.
+func BudgetForModel(ctx context.Context, model string) contextpkg.Budget {
+	length := ModelContextLength(ctx, model)
+	reserve := length / 4
+	if reserve < 512 {
+		reserve = 512
+	}
+	if reserve > 4096 {
+		reserve = 4096
+	}
+	return contextpkg.Budget{ContextLength: length, ReserveForResponse: reserve}
+}
+
+// diffTokenBudget returns how many tokens a diff may use once the other prompt
+// parts (commit message, instructions, ...) have been accounted for.
+func diffTokenBudget(ctx context.Context, otherParts ...string) int {
+	model, _ := GetLLMConfig()
+	budget := BudgetForModel(ctx, model)
+
+	// Instructions, headings and the system prompt of the draft/what templates
+	const promptOverhead = 600
+	available := budget.Available() - promptOverhead
+	for _, part := range otherParts {
+		available -= contextpkg.EstimateTokens(part)
+	}
+	if available < 512 {
+		available = 512
+	}
+	return available
+}
+
+// fitDiff condenses diff to the tokens left after otherParts (see contextpkg.CondenseDiff),
+// logging what had to be left out.
+func fitDiff(ctx context.Context, diff string, otherParts ...string) string {
+	condensed := contextpkg.CondenseDiff(diff, diffTokenBudget(ctx, otherParts...))
+	if len(condensed.SkippedFiles) > 0 {
+		logrus.Infof("[Budget] skipped lockfile/generated/vendored contents: %v", condensed.SkippedFiles)
+	}
+	if condensed.Lossy() {
+		logrus.Warnf("[Budget] %d hunk(s) omitted and %d cut to fit the model context (~%d tokens of diff)",
+			condensed.OmittedHunks, condensed.CutHunks, contextpkg.EstimateTokens(diff))
+	}
+	return condensed.Text
+}
+
+//------------------------------------------------------------------------------
+// BUDGETING CLIENT
+//------------------------------------------------------------------------------
+
+// BudgetingClient wraps another LLMClient and trims every context to the model's
+// window before sending it, logging what had to be dropped.
+type BudgetingClient struct {
+	inner LLMClient
+}
+
+// NewBudgetingClient wraps inner so oversized contexts are trimmed instead of overflowing.
+func NewBudgetingClient(inner LLMClient) *BudgetingClient {
+	return &BudgetingClient{inner: inner}
+}
+
+func (c *BudgetingClient) GetChatResponse(ctx context.Context, messages []contextpkg.Message) (string, error) {
+	return c.inner.GetChatResponse(ctx, fitToModel(ctx, messages))
+}
+
+func (c *BudgetingClient) GetStructuredResponse(ctx context.Context, messages []contextpkg.Message, schema map[string]interface{}) (string, error) {
+	return structuredResponse(ctx, c.inner, fitToModel(ctx, messages), schema)
+}
+
+func (c *BudgetingClient) StreamChatResponse(ctx context.Context, messages []contextpkg.Message) (<-chan string, error) {
+	return c.inner.StreamChatResponse(ctx, fitToModel(ctx, messages))
+}
+
+// fitToModel trims messages to the active model's budget.
+func fitToModel(ctx context.Context, messages []contextpkg.Message) []contextpkg.Message {
+	model, _ := GetLLMConfig()
+	fitted, report := contextpkg.FitMessages(messages, BudgetForModel(ctx, model))
+	if report.Trimmed() {
+		logrus.Warnf("[Budget] %s", report.Summary())
+	}
+	return fitted
+}
@@ -0,0 +1,230 @@
+// internal/llm/commit_message.go
+
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/conventional"
+	"github.com/soyuz43/prbuddy-go/internal/prompts"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// commitMsgWidth is the conventional limit for git subjects and body lines.
+const commitMsgWidth = 72
+
+// recentCommitCount is how many recent subjects are shown to the model as style examples.
+const recentCommitCount = 10
+
+// ErrNothingStaged is returned by GenerateCommitMessage when the index has no changes.
+var ErrNothingStaged = fmt.Errorf("no staged changes")
+
+// GenerateCommitMessage suggests a commit message for the staged changes. It follows
+// Conventional Commits when PRBUDDY_COMMIT_CONVENTION=conventional, or when most recent
+// commits do (=plain turns that off). It can run inside a git hook: an unreachable
+// LLM server fails it at once (see ProbeLLM and WithFailFast), without starting a
+// model or retrying, and it gives up when the OpCommitMsg deadline passes.
+func GenerateCommitMessage(ctx context.Context) (string, error) {
+	ctx, cancel := WithOperationTimeout(WithFailFast(ctx), OpCommitMsg)
+	defer cancel()
+
+	diff, err := utils.GetDiffs(utils.DiffStaged)
+	if err != nil {
+		return "", fmt.Errorf("failed to get staged diff: %w", err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		return "", ErrNothingStaged
+	}
+	if err := ProbeLLM(ctx); err != nil {
+		return "", err
+	}
+
+	type reply struct {
+		message string
+		err     error
+	}
+	done := make(chan reply, 1)
+	go func() {
+		message, err := commitMessage(ctx, diff)
+		done <- reply{message, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.message, r.err
+	case <-ctx.Done():
+		return "", fmt.Errorf("commit message generation: %w", ctx.Err())
+	}
+}
+
+func commitMessage(ctx context.Context, diff string) (string, error) {
+	data := promptData(fitDiff(ctx, diff))
+	data.RecentCommits, data.Conventional = recentCommitStyle()
+
+	prompt, err := prompts.Render(prompts.CommitMsg, data)
+	if err != nil {
+		return "", err
+	}
+	response, err := llmClient.GetChatResponse(ctx, []contextpkg.Message{
+		{Role: "system", Content: "You are a helpful assistant that writes git commit messages."},
+		{Role: "user", Content: prompt},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	message := FormatCommitMessage(response)
+	if message == "" {
+		return "", fmt.Errorf("empty commit message from LLM")
+	}
+	return message, nil
+}
+
+// recentCommitStyle returns recent commit subjects and whether new commits should
+// follow Conventional Commits.
+func recentCommitStyle() ([]string, bool) {
+	var subjects []string
+	if log, err := utils.ExecGit("log", fmt.Sprintf("-%d", recentCommitCount), "--no-merges", "--format=%s"); err == nil && log != "" {
+		subjects = strings.Split(log, "\n")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("PRBUDDY_COMMIT_CONVENTION"))) {
+	case "conventional":
+		return subjects, true
+	case "plain", "none":
+		return subjects, false
+	}
+	return subjects, conventional.Followed(subjects)
+}
+
+// FormatCommitMessage cleans up a model reply into a git commit message: code fences,
+// quotes and "Subject:" labels are dropped, the subject is cut to 72 characters and
+// the body is re-wrapped at 72 columns, keeping list items as separate lines.
+func FormatCommitMessage(raw string) string {
+	text := strings.TrimSpace(utils.StripOuterMarkdownCodeFence(strings.TrimSpace(raw)))
+	text = strings.Trim(text, "\"'`")
+	subject, body, _ := strings.Cut(strings.TrimSpace(text), "\n")
+
+	subject = strings.TrimSpace(subject)
+	for _, label := range []string{"Subject:", "subject:", "Commit message:", "# "} {
+		subject = strings.TrimSpace(strings.TrimPrefix(subject, label))
+	}
+	subject = strings.TrimRight(strings.Trim(subject, "\"'`*"), ".")
+	if len(subject) > commitMsgWidth {
+		subject = truncateAtWord(subject, commitMsgWidth)
+	}
+
+	body = strings.TrimSpace(body)
+	body = strings.TrimSpace(strings.TrimPrefix(body, "Body:"))
+	if body == "" {
+		return subject
+	}
+	return subject + "\n\n" + wrapCommitBody(body, commitMsgWidth)
+}
+
+// wrapCommitBody reflows paragraphs and list items to width. Indented lines are kept
+// as they are, since they are usually code, and so is the trailer block at the end
+// (see splitTrailers), one footer per line.
+func wrapCommitBody(body string, width int) string {
+	var out []string
+	var para []string
+	indent := ""
+	flush := func() {
+		if len(para) > 0 {
+			out = append(out, wrapWords(strings.Join(para, " "), width, indent)...)
+			para, indent = nil, ""
+		}
+	}
+
+	prose, trailers := splitTrailers(strings.Split(body, "\n"))
+	for _, line := range prose {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+		case strings.HasPrefix(line, "    "), strings.HasPrefix(line, "\t"):
+			flush()
+			out = append(out, strings.TrimRight(line, " \t"))
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			flush()
+			para, indent = []string{trimmed}, "  "
+		default:
+			para = append(para, trimmed)
+		}
+	}
+	flush()
+
+	if len(trailers) > 0 {
+		if len(out) > 0 && out[len(out)-1] != "" {
+			out = append(out, "")
+		}
+		out = append(out, trailers...)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// splitTrailers separates the trailer block at the end of a body, following git:
+// only the final paragraph is considered, and the block is its last lines that are
+// all footers ("Refs: #42", "BREAKING CHANGE: ...") or indented continuations,
+// starting at a footer. Lines of prose above them stay in the body.
+func splitTrailers(lines []string) (prose, trailers []string) {
+	start := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			if len(trailers) > 0 {
+				break
+			}
+			start = i
+			continue
+		}
+		if !conventional.IsFooter(line) && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			break
+		}
+		trailers = append([]string{strings.TrimRight(line, " \t")}, trailers...)
+		start = i
+	}
+	// The block starts at its first footer
+	for len(trailers) > 0 && !conventional.IsFooter(trailers[0]) {
+		trailers = trailers[1:]
+		start++
+	}
+	return lines[:start], trailers
+}
+
+// wrapWords greedily fills lines up to width; continuation lines get indent.
+func wrapWords(text string, width int, indent string) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) > width:
+			lines = append(lines, line)
+			line = indent + word
+		default:
+			line += " " + word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// truncateAtWord shortens s to at most limit characters, at a word boundary when possible.
+func truncateAtWord(s string, limit int) string {
+	cut := s[:limit]
+	if i := strings.LastIndex(cut, " "); i > limit/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:-")
+}
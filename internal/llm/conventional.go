@@ -0,0 +1,87 @@
+// internal/llm/conventional.go
+
+package llm
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/conventional"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// messages returns the individual commit messages of the draft input.
+func (in DraftInput) messages() []string {
+	if len(in.Messages) > 0 {
+		return in.Messages
+	}
+	return []string{in.CommitMessage}
+}
+
+// applyConventions shapes the draft after the Conventional Commits in messages: the
+// title gets the PR's type(scope) prefix, breaking changes are called out right under
+// the title, and labels are suggested from the commit types. Drafts for commits that
+// don't follow the convention are left alone.
+func (r *DraftResult) applyConventions(messages []string) {
+	analysis := conventional.Analyze(messages)
+	if !analysis.Conventional() {
+		return
+	}
+	r.Labels = analysis.Labels()
+	logrus.Debugf("Conventional commits: type=%s scope=%s breaking=%v labels=%v",
+		analysis.Type, analysis.Scope, analysis.Breaking, r.Labels)
+
+	if r.Structured != nil {
+		r.Structured.Title = analysis.Title(r.Structured.Title)
+		r.Structured.Breaking = mergeUnique(r.Structured.Breaking, analysis.BreakingNotes())
+		r.Structured.Labels = mergeUnique(r.Structured.Labels, r.Labels)
+		r.Draft = r.Structured.Markdown()
+	} else {
+		r.Draft = retitleMarkdown(utils.StripOuterMarkdownCodeFence(r.Draft), analysis.Title)
+	}
+
+	if callout := analysis.BreakingCallout(); callout != "" {
+		r.Draft = insertAfterTitle(r.Draft, callout)
+	}
+}
+
+// retitleMarkdown rewrites the first H1 of draft with retitle, adding one when missing.
+func retitleMarkdown(draft string, retitle func(string) string) string {
+	lines := strings.Split(draft, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "# ") {
+			lines[i] = "# " + retitle(strings.TrimPrefix(strings.TrimSpace(line), "# "))
+			return strings.Join(lines, "\n")
+		}
+	}
+	return draft
+}
+
+// insertAfterTitle places block directly below the draft's H1, or at the top when there is none.
+func insertAfterTitle(draft, block string) string {
+	lines := strings.Split(draft, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "# ") {
+			rest := strings.TrimLeft(strings.Join(lines[i+1:], "\n"), "\n")
+			return strings.Join(lines[:i+1], "\n") + "\n\n" + block + "\n\n" + rest
+		}
+	}
+	return block + "\n\n" + draft
+}
+
+// mergeUnique appends the items of extra not already in base, case-insensitively.
+func mergeUnique(base, extra []string) []string {
+	for _, e := range extra {
+		found := false
+		for _, b := range base {
+			if strings.EqualFold(strings.TrimSpace(b), strings.TrimSpace(e)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			base = append(base, e)
+		}
+	}
+	return base
+}
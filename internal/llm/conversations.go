@@ -0,0 +1,196 @@
+// internal/llm/conversations.go
+
+package llm
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// Request/response types of the conversation routes
+type (
+	ConversationListRequest struct {
+		Tag string `json:"tag,omitempty"`
+	}
+
+	ConversationSummary struct {
+		ID           string    `json:"id"`
+		Title        string    `json:"title,omitempty"`
+		Tags         []string  `json:"tags,omitempty"`
+		Ephemeral    bool      `json:"ephemeral"`
+		ActiveDCE    bool      `json:"activeDce"`
+		MessageCount int       `json:"messageCount"`
+		LastActivity time.Time `json:"lastActivity"`
+	}
+
+	ConversationListResponse struct {
+		Conversations []ConversationSummary `json:"conversations"`
+	}
+
+	ConversationResponse struct {
+		ID           string               `json:"id"`
+		Title        string               `json:"title,omitempty"`
+		Tags         []string             `json:"tags,omitempty"`
+		Ephemeral    bool                 `json:"ephemeral"`
+		ActiveDCE    bool                 `json:"activeDce"`
+		LastActivity time.Time            `json:"lastActivity"`
+		Messages     []contextpkg.Message `json:"messages"`
+		Tasks        []contextpkg.Task    `json:"tasks"`
+	}
+
+	// ConversationUpdateRequest changes only the fields it sets; an empty title
+	// or tag list clears them.
+	ConversationUpdateRequest struct {
+		Title *string  `json:"title,omitempty"`
+		Tags  []string `json:"tags,omitempty"`
+	}
+
+	ConversationExportRequest struct {
+		// "markdown" (default) or "json"
+		Format string `json:"format,omitempty"`
+	}
+)
+
+func conversationRoutes() []apiRoute {
+	return []apiRoute{
+		{
+			Method: http.MethodGet, Path: "/v1/conversations", Summary: "List the conversations in memory, most recent first",
+			Request: ConversationListRequest{}, Response: ConversationListResponse{},
+			Handler: apiHandler(func(_ *http.Request, req ConversationListRequest) (ConversationListResponse, error) {
+				list := ConversationListResponse{Conversations: []ConversationSummary{}}
+				for _, snap := range contextpkg.ConversationManagerInstance.Snapshots() {
+					if req.Tag == "" || snap.HasTag(req.Tag) {
+						list.Conversations = append(list.Conversations, conversationSummary(snap))
+					}
+				}
+				return list, nil
+			}),
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/conversations/{id}", Summary: "Get a conversation with its messages and tasks",
+			Response: ConversationResponse{},
+			Handler: apiHandler(func(r *http.Request, _ struct{}) (ConversationResponse, error) {
+				snap, err := conversationSnapshot(r)
+				return conversationResponse(snap), err
+			}),
+		},
+		{
+			Method: http.MethodPatch, Path: "/v1/conversations/{id}", Summary: "Rename or tag a conversation",
+			Request: ConversationUpdateRequest{}, Response: ConversationSummary{},
+			Handler: apiHandler(func(r *http.Request, req ConversationUpdateRequest) (ConversationSummary, error) {
+				id := r.PathValue("id")
+				if _, err := conversationSnapshot(r); err != nil {
+					return ConversationSummary{}, err
+				}
+				if req.Title != nil {
+					contextpkg.ConversationManagerInstance.Rename(id, *req.Title)
+				}
+				if req.Tags != nil {
+					contextpkg.ConversationManagerInstance.SetTags(id, req.Tags)
+				}
+				snap, err := conversationSnapshot(r)
+				return conversationSummary(snap), err
+			}),
+		},
+		{
+			Method: http.MethodDelete, Path: "/v1/conversations/{id}", Summary: "Delete a conversation",
+			Response: StatusResponse{},
+			Handler: apiHandler(func(r *http.Request, _ struct{}) (StatusResponse, error) {
+				if _, err := conversationSnapshot(r); err != nil {
+					return StatusResponse{}, err
+				}
+				contextpkg.ConversationManagerInstance.RemoveConversation(r.PathValue("id"))
+				return StatusResponse{Status: "deleted"}, nil
+			}),
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/conversations/{id}/export", Summary: "Download a conversation as markdown or JSON",
+			Request: ConversationExportRequest{}, Response: ConversationResponse{}, Produces: "text/markdown",
+			Handler: exportConversationHandler,
+		},
+	}
+}
+
+// conversationSnapshot copies the conversation named by the {id} path parameter.
+func conversationSnapshot(r *http.Request) (contextpkg.ConversationSnapshot, error) {
+	id := r.PathValue("id")
+	snap, ok := contextpkg.ConversationManagerInstance.Snapshot(id)
+	if !ok {
+		return snap, newAPIError(http.StatusNotFound, CodeNotFound, "Conversation %q not found", id)
+	}
+	return snap, nil
+}
+
+func conversationSummary(snap contextpkg.ConversationSnapshot) ConversationSummary {
+	return ConversationSummary{
+		ID:           snap.ID,
+		Title:        snap.Title,
+		Tags:         snap.Tags,
+		Ephemeral:    snap.Ephemeral,
+		ActiveDCE:    snap.ActiveDCE,
+		MessageCount: len(snap.Messages),
+		LastActivity: snap.LastActivity.UTC(),
+	}
+}
+
+func conversationResponse(snap contextpkg.ConversationSnapshot) ConversationResponse {
+	resp := ConversationResponse{
+		ID:           snap.ID,
+		Title:        snap.Title,
+		Tags:         snap.Tags,
+		Ephemeral:    snap.Ephemeral,
+		ActiveDCE:    snap.ActiveDCE,
+		LastActivity: snap.LastActivity.UTC(),
+		Messages:     snap.Messages,
+		Tasks:        snap.Tasks,
+	}
+	// Empty lists rather than null, for clients that iterate them
+	if resp.Messages == nil {
+		resp.Messages = []contextpkg.Message{}
+	}
+	if resp.Tasks == nil {
+		resp.Tasks = []contextpkg.Task{}
+	}
+	return resp
+}
+
+var unsafeFileNameRe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// exportConversationHandler sends a conversation as a file download.
+func exportConversationHandler(w http.ResponseWriter, r *http.Request) {
+	var req ConversationExportRequest
+	if err := decodeQuery(r.URL.Query(), &req); err != nil {
+		writeError(w, invalidRequest("Invalid query: %v", err))
+		return
+	}
+	snap, err := conversationSnapshot(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var body, contentType, ext string
+	switch req.Format {
+	case "", "markdown", "md":
+		body, contentType, ext = snap.Markdown(), "text/markdown; charset=utf-8", "md"
+	case "json":
+		if body, err = utils.MarshalJSON(conversationResponse(snap)); err != nil {
+			writeError(w, newAPIError(http.StatusInternalServerError, CodeInternal, "Failed to marshal response"))
+			return
+		}
+		contentType, ext = "application/json", "json"
+	default:
+		writeError(w, invalidRequest("format must be markdown or json, not %q", req.Format))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, unsafeFileNameRe.ReplaceAllString(snap.ID, "_"), ext))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(body))
+}
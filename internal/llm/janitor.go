@@ -0,0 +1,151 @@
+// internal/llm/janitor.go
+
+package llm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+const (
+	defaultJanitorInterval         = time.Minute
+	defaultConversationMaxIdle     = time.Hour
+	defaultConversationMaxMessages = 5000
+	defaultConversationMaxBytes    = 64 << 20
+)
+
+// JanitorConfig controls how the server expires its conversations.
+type JanitorConfig struct {
+	Interval time.Duration
+	Policy   contextpkg.ExpiryPolicy
+	// Persist saves evicted non-ephemeral conversations to
+	// .git/pr_buddy_db/conversations before they are dropped
+	Persist bool
+}
+
+// JanitorConfigFromEnv returns the defaults, overridden by
+// PRBUDDY_CONVERSATION_MAX_IDLE (a duration), PRBUDDY_CONVERSATION_MAX_MESSAGES,
+// PRBUDDY_CONVERSATION_MAX_BYTES and PRBUDDY_PERSIST_EVICTED (a boolean).
+func JanitorConfigFromEnv() JanitorConfig {
+	cfg := JanitorConfig{
+		Interval: defaultJanitorInterval,
+		Policy: contextpkg.ExpiryPolicy{
+			MaxIdle:     defaultConversationMaxIdle,
+			MaxMessages: defaultConversationMaxMessages,
+			MaxBytes:    defaultConversationMaxBytes,
+		},
+	}
+	if raw := os.Getenv("PRBUDDY_CONVERSATION_MAX_IDLE"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			cfg.Policy.MaxIdle = d
+		} else {
+			logrus.Warnf("Ignoring invalid PRBUDDY_CONVERSATION_MAX_IDLE=%q", raw)
+		}
+	}
+	if n := envInt("PRBUDDY_CONVERSATION_MAX_MESSAGES"); n > 0 {
+		cfg.Policy.MaxMessages = n
+	}
+	if n := envInt("PRBUDDY_CONVERSATION_MAX_BYTES"); n > 0 {
+		cfg.Policy.MaxBytes = n
+	}
+	if raw := os.Getenv("PRBUDDY_PERSIST_EVICTED"); raw != "" {
+		if persist, err := strconv.ParseBool(raw); err == nil {
+			cfg.Persist = persist
+		} else {
+			logrus.Warnf("Ignoring invalid PRBUDDY_PERSIST_EVICTED=%q", raw)
+		}
+	}
+	return cfg
+}
+
+// Janitor expires conversations in the background until stopped.
+type Janitor struct {
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// StartJanitor sweeps cm every cfg.Interval, or every minute when it isn't set.
+func StartJanitor(cm *contextpkg.ConversationManager, cfg JanitorConfig) *Janitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultJanitorInterval
+	}
+	j := &Janitor{stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(j.done)
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-j.stop:
+				return
+			case <-ticker.C:
+				ExpireConversations(cm, cfg)
+			}
+		}
+	}()
+	return j
+}
+
+// Stop ends the janitor, waiting for a sweep in progress. It is safe to call more than once.
+func (j *Janitor) Stop() {
+	j.stopOnce.Do(func() { close(j.stop) })
+	<-j.done
+}
+
+// ExpireConversations sweeps cm once, logging every eviction.
+func ExpireConversations(cm *contextpkg.ConversationManager, cfg JanitorConfig) []contextpkg.Eviction {
+	saved := make(map[string]string)
+	var beforeEvict func(contextpkg.ConversationSnapshot) error
+	if cfg.Persist {
+		beforeEvict = func(snap contextpkg.ConversationSnapshot) error {
+			if snap.Ephemeral {
+				return nil
+			}
+			path, err := saveEvictedConversation(snap)
+			if err != nil {
+				logrus.Warnf("[Janitor] Keeping conversation %s: %v", snap.ID, err)
+				return err
+			}
+			saved[snap.ID] = path
+			return nil
+		}
+	}
+
+	evicted := cm.Expire(cfg.Policy, beforeEvict)
+	for _, e := range evicted {
+		if path, ok := saved[e.Snapshot.ID]; ok {
+			logrus.Infof("[Janitor] Evicted conversation %s (%d messages, %s); saved to %s", e.Snapshot.ID, len(e.Snapshot.Messages), e.Reason, path)
+		} else {
+			logrus.Infof("[Janitor] Evicted conversation %s (%d messages, %s)", e.Snapshot.ID, len(e.Snapshot.Messages), e.Reason)
+		}
+	}
+	return evicted
+}
+
+// saveEvictedConversation writes snap in the JSON export format and returns the file's path.
+func saveEvictedConversation(snap contextpkg.ConversationSnapshot) (string, error) {
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository path: %w", err)
+	}
+
+	content, err := utils.MarshalJSON(conversationResponse(snap))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal conversation: %w", err)
+	}
+
+	path := filepath.Join(repoPath, ".git", "pr_buddy_db", "conversations", unsafeFileNameRe.ReplaceAllString(snap.ID, "_")+".json")
+	if err := utils.WriteFile(path, []byte(content)); err != nil {
+		return "", fmt.Errorf("failed to save conversation: %w", err)
+	}
+	return path, nil
+}
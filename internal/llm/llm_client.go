@@ -5,6 +5,7 @@ package llm
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -17,6 +18,8 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
 	"github.com/soyuz43/prbuddy-go/internal/dce"
+	"github.com/soyuz43/prbuddy-go/internal/prompts"
+	"github.com/soyuz43/prbuddy-go/internal/prtemplate"
 	"github.com/soyuz43/prbuddy-go/internal/utils"
 )
 
@@ -24,12 +27,28 @@ import (
 // LLMClient INTERFACE + DEFAULT IMPLEMENTATION
 //------------------------------------------------------------------------------
 
-// LLMClient defines the interface for interacting with the LLM (Ollama).
+// LLMClient defines the interface for interacting with the LLM (Ollama or an OpenAI-compatible server).
 type LLMClient interface {
 	// For non-streaming calls
-	GetChatResponse(messages []contextpkg.Message) (string, error)
-	// For streaming calls (accumulate chunks under the hood)
-	StreamChatResponse(messages []contextpkg.Message) (<-chan string, error)
+	GetChatResponse(ctx context.Context, messages []contextpkg.Message) (string, error)
+	// For streaming calls (accumulate chunks under the hood); the channel closes
+	// when generation finishes or ctx is cancelled.
+	StreamChatResponse(ctx context.Context, messages []contextpkg.Message) (<-chan string, error)
+}
+
+// StructuredLLMClient is implemented by clients that can constrain a response to a
+// JSON schema (Ollama's "format", OpenAI's "response_format").
+type StructuredLLMClient interface {
+	GetStructuredResponse(ctx context.Context, messages []contextpkg.Message, schema map[string]interface{}) (string, error)
+}
+
+// structuredResponse asks client for JSON matching schema, falling back to a plain
+// chat call (the prompt must then ask for JSON itself) when it can't constrain output.
+func structuredResponse(ctx context.Context, client LLMClient, messages []contextpkg.Message, schema map[string]interface{}) (string, error) {
+	if sc, ok := client.(StructuredLLMClient); ok {
+		return sc.GetStructuredResponse(ctx, messages, schema)
+	}
+	return client.GetChatResponse(ctx, messages)
 }
 
 // DefaultLLMClient implements the LLMClient interface using Ollama’s /api/chat.
@@ -39,7 +58,16 @@ type DefaultLLMClient struct{}
 // NON-STREAMING METHOD: GetChatResponse
 //------------------------------------------------------------------------------
 
-func (c *DefaultLLMClient) GetChatResponse(messages []contextpkg.Message) (string, error) {
+func (c *DefaultLLMClient) GetChatResponse(ctx context.Context, messages []contextpkg.Message) (string, error) {
+	return c.chat(ctx, messages, nil)
+}
+
+// GetStructuredResponse passes schema as Ollama's "format" so the reply is valid JSON for it.
+func (c *DefaultLLMClient) GetStructuredResponse(ctx context.Context, messages []contextpkg.Message, schema map[string]interface{}) (string, error) {
+	return c.chat(ctx, messages, schema)
+}
+
+func (c *DefaultLLMClient) chat(ctx context.Context, messages []contextpkg.Message, schema map[string]interface{}) (string, error) {
 	model, endpoint := GetLLMConfig()
 
 	// Request body: force "stream": false
@@ -47,24 +75,33 @@ func (c *DefaultLLMClient) GetChatResponse(messages []contextpkg.Message) (strin
 		"model":    model,
 		"messages": messages,
 		"options": map[string]interface{}{
-			"num_ctx": 8192,
+			"num_ctx": ModelContextLength(ctx, model),
 		},
 		"stream": false,
 	}
+	if schema != nil {
+		requestBody["format"] = schema
+	}
 
 	jsonBody, err := utils.MarshalJSON(requestBody)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to marshal request body")
 	}
 
-	resp, err := http.Post(endpoint+"/api/chat", "application/json", strings.NewReader(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint+"/api/chat", strings.NewReader(jsonBody))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to send POST request to LLM")
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("LLM responded with status code %d", resp.StatusCode)
+		return "", &StatusError{StatusCode: resp.StatusCode}
 	}
 
 	var llmResp LLMResponse
@@ -86,7 +123,7 @@ func (c *DefaultLLMClient) GetChatResponse(messages []contextpkg.Message) (strin
 
 // StreamChatResponse reads lines from Ollama’s /api/chat as soon as they arrive.
 // Each line is expected to be a complete JSON object. When "done" = true, we stop.
-func (c *DefaultLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-chan string, error) {
+func (c *DefaultLLMClient) StreamChatResponse(ctx context.Context, messages []contextpkg.Message) (<-chan string, error) {
 	model, endpoint := GetLLMConfig()
 
 	reqBody := map[string]interface{}{
@@ -94,7 +131,7 @@ func (c *DefaultLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-
 		"messages": messages,
 		"stream":   true,
 		"options": map[string]interface{}{
-			"num_ctx": 8192,
+			"num_ctx": ModelContextLength(ctx, model),
 		},
 	}
 
@@ -103,7 +140,7 @@ func (c *DefaultLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", endpoint+"/api/chat", bytes.NewReader(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint+"/api/chat", bytes.NewReader(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -116,7 +153,7 @@ func (c *DefaultLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-
 	}
 	if resp.StatusCode != http.StatusOK {
 		resp.Body.Close()
-		return nil, fmt.Errorf("non-200 status code: %d", resp.StatusCode)
+		return nil, &StatusError{StatusCode: resp.StatusCode}
 	}
 
 	outChan := make(chan string)
@@ -144,14 +181,18 @@ func (c *DefaultLLMClient) StreamChatResponse(messages []contextpkg.Message) (<-
 				break
 			}
 
-			// Send content if present
+			// Send content if present; stop if the caller has gone away
 			if chunk.Message != nil && chunk.Message.Content != "" {
-				outChan <- chunk.Message.Content
+				select {
+				case outChan <- chunk.Message.Content:
+				case <-ctx.Done():
+					return
+				}
 			}
 		}
 
-		// If there's a scanning error, log it
-		if err := scanner.Err(); err != nil {
+		// If there's a scanning error, log it (cancellation surfaces here too)
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
 			logrus.Errorf("Scanner error while reading streaming response: %v", err)
 		}
 	}()
@@ -181,8 +222,10 @@ type OllamaStreamChunk struct {
 	Done bool `json:"done,omitempty"`
 }
 
-// llmClient is the global instance implementing LLMClient.
-var llmClient LLMClient = &DefaultLLMClient{}
+// llmClient is the global instance implementing LLMClient, chosen by PRBUDDY_LLM_PROVIDER,
+// wrapped so contexts are trimmed to the model's window and transient failures
+// (Ollama restarts, cold loads) are retried.
+var llmClient LLMClient = NewBudgetingClient(NewRetryingClient(newLLMClient(), DefaultRetryPolicy()))
 
 // SetLLMClient allows injecting a different LLMClient (useful for testing or future extensions).
 func SetLLMClient(client LLMClient) {
@@ -195,11 +238,22 @@ func SetLLMClient(client LLMClient) {
 
 // HandleQuickAssist returns the final LLM response for a persistent conversation,
 // accumulating the streaming output behind-the-scenes into one string.
-func HandleQuickAssist(conversationID, input string) (string, error) {
+func HandleQuickAssist(ctx context.Context, conversationID, input string) (string, error) {
+	_, response, err := StreamQuickAssist(ctx, conversationID, input, nil)
+	return response, err
+}
+
+// StreamQuickAssist is HandleQuickAssist passing each chunk of the response to
+// onChunk as it arrives. It returns the conversation ID, generated when empty,
+// and the whole response.
+func StreamQuickAssist(ctx context.Context, conversationID, input string, onChunk ChunkFunc) (string, string, error) {
 	if input == "" {
-		return "", fmt.Errorf("no user message provided")
+		return "", "", invalidRequest("no user message provided")
 	}
 
+	ctx, cancel := WithOperationTimeout(ctx, OpQuickAssist)
+	defer cancel()
+
 	// Retrieve or create conversation
 	conv, exists := contextpkg.ConversationManagerInstance.GetConversation(conversationID)
 	if !exists {
@@ -212,35 +266,48 @@ func HandleQuickAssist(conversationID, input string) (string, error) {
 	// 1) Add user's message
 	conv.AddMessage("user", input)
 
-	// 2) Build final context for LLM
-	context := conv.BuildContext()
+	// 2) Build final context for LLM, using the (customisable) quickassist system prompt
+	chatContext := conv.BuildContext()
+	if system, err := prompts.Render(prompts.QuickAssist, promptData("")); err == nil && len(chatContext) > 0 {
+		chatContext[0].Content = system
+	}
 
 	// 3) Stream from LLM
-	streamChan, err := llmClient.StreamChatResponse(context)
+	streamChan, err := llmClient.StreamChatResponse(ctx, chatContext)
 	if err != nil {
-		return "", fmt.Errorf("failed to stream response: %w", err)
+		return conv.ID, "", fmt.Errorf("failed to stream response: %w", err)
 	}
 
-	// 4) Collect the streaming chunks
-	var builder strings.Builder
-	for chunk := range streamChan {
-		builder.WriteString(chunk)
+	// 4) Collect the streaming chunks, forwarding them to onChunk
+	finalResponse, err := collectStream(ctx, streamChan, onChunk)
+	if err != nil {
+		return conv.ID, "", fmt.Errorf("quickassist interrupted: %w", err)
 	}
-	finalResponse := builder.String()
 
 	// 5) Store assistant's final response in conversation
 	conv.AddMessage("assistant", finalResponse)
 
-	return finalResponse, nil
+	return conv.ID, finalResponse, nil
 }
 
 // HandleDCERequest handles ephemeral (DCE-driven) requests, returning the final text
 // from a fresh ephemeral conversation, after running your DCE logic.
-func HandleDCERequest(conversationID, input string) (string, error) {
+func HandleDCERequest(ctx context.Context, conversationID, input string) (string, error) {
+	_, response, err := StreamDCERequest(ctx, conversationID, input, nil)
+	return response, err
+}
+
+// StreamDCERequest is HandleDCERequest streaming the response through onChunk;
+// with a nil onChunk the response is requested in one piece. It returns the
+// conversation ID and the whole response.
+func StreamDCERequest(ctx context.Context, conversationID, input string, onChunk ChunkFunc) (string, string, error) {
 	if input == "" {
-		return "", fmt.Errorf("no user message provided")
+		return "", "", invalidRequest("no user message provided")
 	}
 
+	ctx, cancel := WithOperationTimeout(ctx, OpQuickAssist)
+	defer cancel()
+
 	// Get or create ephemeral conversation
 	conv, exists := contextpkg.ConversationManagerInstance.GetConversation(conversationID)
 	if !exists {
@@ -255,14 +322,14 @@ func HandleDCERequest(conversationID, input string) (string, error) {
 	// Initialize and use DCE
 	dceInstance := dce.NewDCE()
 	if err := dceInstance.Activate(input); err != nil {
-		return "", fmt.Errorf("DCE activation failed: %w", err)
+		return conv.ID, "", fmt.Errorf("DCE activation failed: %w", err)
 	}
 	defer dceInstance.Deactivate(conversationID)
 
 	// Build task list
 	taskList, _, buildLogs, err := dceInstance.BuildTaskList(input)
 	if err != nil {
-		return "", fmt.Errorf("failed to build task list: %w", err)
+		return conv.ID, "", fmt.Errorf("failed to build task list: %w", err)
 	}
 
 	fmt.Println("=== Task List ===")
@@ -293,7 +360,7 @@ func HandleDCERequest(conversationID, input string) (string, error) {
 	// Filter project data
 	filteredData, filterLogs, err := dceInstance.FilterProjectData(taskList)
 	if err != nil {
-		return "", fmt.Errorf("failed to filter project data: %w", err)
+		return conv.ID, "", fmt.Errorf("failed to filter project data: %w", err)
 	}
 	for _, logMsg := range filterLogs {
 		conv.AddMessage("system", "[DCE] "+logMsg)
@@ -313,41 +380,40 @@ func HandleDCERequest(conversationID, input string) (string, error) {
 	}
 
 	// Build final context
-	context := conv.BuildContext()
+	chatContext := conv.BuildContext()
 
-	// Retrieve response (non-streaming) from LLM
-	response, err := llmClient.GetChatResponse(context)
+	// Retrieve response from LLM, streamed when the caller wants the chunks
+	response, err := respond(ctx, chatContext, onChunk)
 	if err != nil {
-		return "", fmt.Errorf("failed to get response from LLM: %w", err)
+		return conv.ID, "", fmt.Errorf("failed to get response from LLM: %w", err)
 	}
 
 	conv.AddMessage("assistant", response)
-	return response, nil
+	return conv.ID, response, nil
 }
 
 // StartPRConversation initiates a new PR conversation with a commit message and diffs.
-func StartPRConversation(commitMessage, diffs string) (string, string, error) {
+func StartPRConversation(ctx context.Context, commitMessage, diffs string) (string, string, error) {
+	ctx, cancel := WithOperationTimeout(ctx, OpDraft)
+	defer cancel()
+
 	// Generate a conversation ID
 	conversationID := fmt.Sprintf("pr-%d", time.Now().UnixNano())
 	conv := contextpkg.ConversationManagerInstance.StartConversation(conversationID, diffs, false)
 
-	prompt := fmt.Sprintf(`
-You are an assistant designed to generate a detailed pull request (PR) description based on the following commit message and code changes.
-
-**Commit Message:**
-%s
-
-**Code Changes:**
-%s
-
-!TASK: Provide a comprehensive PR title and description that explain the changes and adhere to documentation and GitHub best practices. Format the pull request in raw markdown with headers. Clearly separate the pull request and other components of the response with three backticks. In fact, wrap the entire output in triple backticks. The entire output must be a single raw markdown code block, with no additional commentary or explanation outside the code block. No emojis in output.
-`, commitMessage, diffs)
+	data := promptData(diffs)
+	data.CommitMessage = commitMessage
+	withPRTemplate(&data)
+	prompt, err := prompts.Render(prompts.Draft, data)
+	if err != nil {
+		return "", "", err
+	}
 
 	// Add initial user message
 	conv.AddMessage("user", prompt)
 
 	// Get initial response (non-streaming)
-	response, err := llmClient.GetChatResponse(conv.BuildContext())
+	response, err := llmClient.GetChatResponse(ctx, conv.BuildContext())
 	if err != nil {
 		return "", "", err
 	}
@@ -357,13 +423,61 @@ You are an assistant designed to generate a detailed pull request (PR) descripti
 	return conversationID, response, nil
 }
 
-// ContinuePRConversation reuses HandleQuickAssist for continuing a normal (persistent) PR conversation.
-func ContinuePRConversation(conversationID, input string) (string, error) {
-	return HandleQuickAssist(conversationID, input)
+// StartPRConversationFromDraft opens a PR conversation around a draft that was already
+// generated (and saved), so it can be refined without drafting from scratch again.
+func StartPRConversationFromDraft(ctx context.Context, commitMessage, diffs, draft string) (string, error) {
+	data := promptData(fitDiff(ctx, diffs, commitMessage, draft))
+	data.CommitMessage = commitMessage
+	withPRTemplate(&data)
+	prompt, err := prompts.Render(prompts.Draft, data)
+	if err != nil {
+		return "", err
+	}
+
+	conversationID := contextpkg.GenerateConversationID("pr")
+	conv := contextpkg.ConversationManagerInstance.StartConversation(conversationID, diffs, false)
+	conv.AddMessage("user", prompt)
+	conv.AddMessage("assistant", "```markdown\n"+strings.TrimSpace(draft)+"\n```")
+	return conversationID, nil
+}
+
+// ContinuePRConversation asks for changes to the draft of a PR conversation ("shorter",
+// "add a testing section") and returns the complete revised draft. A failed request
+// is dropped from the conversation so it can simply be retried.
+func ContinuePRConversation(ctx context.Context, conversationID, input string) (string, error) {
+	if strings.TrimSpace(input) == "" {
+		return "", fmt.Errorf("no user message provided")
+	}
+	conv, exists := contextpkg.ConversationManagerInstance.GetConversation(conversationID)
+	if !exists {
+		return "", fmt.Errorf("unknown PR conversation %q", conversationID)
+	}
+
+	ctx, cancel := WithOperationTimeout(ctx, OpDraft)
+	defer cancel()
+
+	before := conv.BuildContext()[1:]
+	conv.AddMessage("user", input+"\n\nReply with the complete revised pull request description "+
+		"in raw markdown, starting with \"# <PR title>\", wrapped in triple backticks, with no other commentary.")
+
+	response, err := llmClient.GetChatResponse(ctx, conv.BuildContext())
+	if err != nil {
+		conv.SetMessages(before)
+		return "", err
+	}
+	draft := strings.TrimSpace(utils.StripOuterMarkdownCodeFence(response))
+	if draft == "" {
+		conv.SetMessages(before)
+		return "", fmt.Errorf("empty response from LLM")
+	}
+
+	conv.AddMessage("assistant", response)
+	return draft, nil
 }
 
 // GeneratePreDraftPR obtains the latest commit message and diff, then returns them for usage in PR creation.
-func GeneratePreDraftPR() (string, string, error) {
+// The diff is trimmed to what the active model's context window can hold next to the message.
+func GeneratePreDraftPR(ctx context.Context) (string, string, error) {
 	commitMsg, err := utils.ExecGit("log", "-1", "--pretty=%B")
 	if err != nil {
 		return "", "", errors.Wrap(err, "failed to get latest commit message")
@@ -373,48 +487,91 @@ func GeneratePreDraftPR() (string, string, error) {
 		return "", "", errors.Wrap(err, "failed to get git diff")
 	}
 
-	// Intelligent truncation: prioritize added lines and metadata
-	truncatedDiff := contextpkg.TruncateDiff(diff, 1000) // Adjust max lines as needed
-	return commitMsg, truncatedDiff, nil
+	return commitMsg, fitDiff(ctx, diff, commitMsg), nil
 }
 
 // GenerateDraftPR uses the LLM's chat endpoint to generate a PR draft (stateless).
-func GenerateDraftPR(commitMessage, diffs string) (string, error) {
-	prompt := fmt.Sprintf(`
-/contextualize: You are a developer, tasked to generate a detailed pull request (PR) description based on the following commit message and code changes.
+func GenerateDraftPR(ctx context.Context, commitMessage, diffs string) (string, error) {
+	ctx, cancel := WithOperationTimeout(ctx, OpDraft)
+	defer cancel()
 
-**Commit Message:**
-%s
-
-**Code Changes:**
-%s
+	data := promptData(diffs)
+	data.CommitMessage = commitMessage
+	return generateDraftFromChanges(ctx, data)
+}
 
-!TASK: Provide a comprehensive PR title and description that explain the changes and adhere to documentation and GitHub best practices. Format the pull request in raw markdown with headers. Clearly separate the pull request and other components of the response with three backticks. In fact, wrap the entire output in triple backticks. The entire output must be a single raw markdown code block, with no additional commentary or explanation outside the code block.
-`, commitMessage, diffs)
+// generateDraftFromChanges renders the draft template over data, whose Diff is either
+// the diff itself or per-file summaries of it (see GenerateDraft). When the repository
+// has a pull request template the draft is checked to contain all of its headings.
+func generateDraftFromChanges(ctx context.Context, data prompts.Data) (string, error) {
+	tmpl := withPRTemplate(&data)
+	prompt, err := prompts.Render(prompts.Draft, data)
+	if err != nil {
+		return "", err
+	}
 
 	statelessMessages := []contextpkg.Message{
 		{Role: "system", Content: "You are a helpful assistant."},
 		{Role: "user", Content: prompt},
 	}
 
-	response, err := llmClient.GetChatResponse(statelessMessages)
+	response, err := llmClient.GetChatResponse(ctx, statelessMessages)
 	if err != nil {
 		return "", err
 	}
-	return response, nil
+	if tmpl == nil || len(tmpl.Headings) == 0 {
+		return response, nil
+	}
+	return ensureTemplateHeadings(ctx, statelessMessages, response, tmpl)
+}
+
+// ensureTemplateHeadings re-prompts once when the draft dropped sections of the PR
+// template, then fills whatever is still missing with placeholders.
+func ensureTemplateHeadings(ctx context.Context, messages []contextpkg.Message, response string, tmpl *prtemplate.Template) (string, error) {
+	draft := utils.StripOuterMarkdownCodeFence(response)
+	missing := prtemplate.MissingHeadings(draft, tmpl.Headings)
+	if len(missing) == 0 {
+		return response, nil
+	}
+
+	logrus.Warnf("Draft is missing PR template section(s) %q; asking the model to fix it", missing)
+	retry := append(append([]contextpkg.Message(nil), messages...),
+		contextpkg.Message{Role: "assistant", Content: response},
+		contextpkg.Message{Role: "user", Content: fmt.Sprintf(
+			"The description is missing these required sections of the pull request template: %s. "+
+				"Reply with the complete pull request description again, containing every section (%s), wrapped in triple backticks.",
+			strings.Join(missing, ", "), strings.Join(tmpl.Headings, ", "))},
+	)
+	if second, err := llmClient.GetChatResponse(ctx, retry); err == nil {
+		draft = utils.StripOuterMarkdownCodeFence(second)
+		missing = prtemplate.MissingHeadings(draft, tmpl.Headings)
+	} else if ctx.Err() != nil {
+		return "", err
+	} else {
+		logrus.Warnf("Re-prompt for missing sections failed: %v", err)
+	}
+
+	if len(missing) > 0 {
+		logrus.Warnf("Adding placeholder section(s) for %q from %s", missing, tmpl.Path)
+	}
+	return prtemplate.FillMissing(draft, missing), nil
 }
 
 // GenerateWhatSummaryWithDCEContext generates a summary of git diffs using the LLM with integrated DCE context
 // This provides a more contextualized summary by leveraging the Dynamic Context Engine's understanding of tasks
-func GenerateWhatSummaryWithDCEContext() (string, error) {
+func GenerateWhatSummaryWithDCEContext(ctx context.Context) (string, error) {
+	ctx, cancel := WithOperationTimeout(ctx, OpWhat)
+	defer cancel()
+
 	// 1. Get diffs (same as the original function)
 	diffs, err := utils.GetDiffs(utils.DiffAllLocalChanges)
 	if err != nil {
 		return "", fmt.Errorf("failed to get diffs: %w", err)
 	}
 	if diffs == "" {
-		return "No changes detected since the last commit.", nil
+		return noChangesSummary, nil
 	}
+	diffs = fitDiff(ctx, diffs)
 
 	// 2. Create a conversation ID and get or create conversation
 	conversationID := contextpkg.GenerateConversationID("what-dce")
@@ -423,32 +580,26 @@ func GenerateWhatSummaryWithDCEContext() (string, error) {
 		conv = contextpkg.ConversationManagerInstance.StartConversation(conversationID, "", true)
 	}
 
-	// 3. Create the prompt for the LLM (same as original)
-	prompt := fmt.Sprintf(`
-These are the git diffs for the repository:
-
-%s
-
----
-!TASK::
-1. Provide a meticulous natural language summary of each of the changes. Do so by file. Describe each change made in full.
-2. List and separate changes for each file changed using numbered points and markdown formatting.
-3. Only describe the changes explicitly present in the diffs. Do not infer, speculate, or invent additional content.
-4. Focus on helping the developer reorient themselves and understand where they left off.
-`, diffs)
-
-	// 4. Add user message to conversation
-	conv.AddMessage("user", prompt)
-
-	// 5. Initialize DCE
+	// 3. Initialize DCE
 	dceInstance := dce.NewDCE()
 
-	// 6. Build task list using a descriptive input that captures our intent
+	// 4. Build task list using a descriptive input that captures our intent
 	taskList, _, buildLogs, err := dceInstance.BuildTaskList("Summarizing recent changes and providing context-aware summary of current development progress")
 	if err != nil {
 		return "", fmt.Errorf("failed to build task list: %w", err)
 	}
 
+	// 5. Render the prompt, including the DCE tasks
+	data := promptData(diffs)
+	data.Tasks = taskList
+	prompt, err := prompts.Render(prompts.What, data)
+	if err != nil {
+		return "", err
+	}
+
+	// 6. Add user message to conversation
+	conv.AddMessage("user", prompt)
+
 	// 7. Add build logs to conversation and console
 	for _, logMsg := range buildLogs {
 		conv.AddMessage("system", "[DCE] "+logMsg)
@@ -478,7 +629,7 @@ These are the git diffs for the repository:
 	}
 
 	// 11. Get response from LLM with the augmented context
-	response, err := llmClient.GetChatResponse(augmentedContext)
+	response, err := llmClient.GetChatResponse(ctx, augmentedContext)
 	if err != nil {
 		return "", fmt.Errorf("failed to get response from LLM: %w", err)
 	}
@@ -489,45 +640,117 @@ These are the git diffs for the repository:
 }
 
 // GenerateWhatSummary generates a summary of git diffs using the LLM (stateless).
-func GenerateWhatSummary() (string, error) {
+func GenerateWhatSummary(ctx context.Context) (string, error) {
+	return StreamWhatSummary(ctx, nil)
+}
+
+// StreamWhatSummary is GenerateWhatSummary streaming the summary through onChunk.
+func StreamWhatSummary(ctx context.Context, onChunk ChunkFunc) (string, error) {
+	ctx, cancel := WithOperationTimeout(ctx, OpWhat)
+	defer cancel()
+
 	diffs, err := utils.GetDiffs(utils.DiffAllLocalChanges)
 	if err != nil {
 		return "", fmt.Errorf("failed to get diffs: %w", err)
 	}
 	if diffs == "" {
-		return "No changes detected since the last commit.", nil
+		return respondWith(noChangesSummary, onChunk)
 	}
+	diffs = fitDiff(ctx, diffs)
 
-	prompt := fmt.Sprintf(`
-These are the git diffs for the repository:
-
-%s
-
----
-!TASK::
-1. Provide a meticulous natural language summary of each of the changes. Do so by file. Describe each change made in full.
-2. List and separate changes for each file changed using numbered points and markdown formatting.
-3. Only describe the changes explicitly present in the diffs. Do not infer, speculate, or invent additional content.
-4. Focus on helping the developer reorient themselves and understand where they left off.
-`, diffs)
+	prompt, err := prompts.Render(prompts.What, promptData(diffs))
+	if err != nil {
+		return "", err
+	}
 
 	statelessMessages := []contextpkg.Message{
 		{Role: "system", Content: "You are a helpful assistant."},
 		{Role: "user", Content: prompt},
 	}
 
-	return llmClient.GetChatResponse(statelessMessages)
+	return respond(ctx, statelessMessages, onChunk)
 }
 
 // ------------------------------------------------------------------------------
 // UTILITY FUNCTIONS: LLM config resolution + model readiness
 // ------------------------------------------------------------------------------
 
-func GetLLMConfig() (string, string) {
-	endpoint := os.Getenv("PRBUDDY_LLM_ENDPOINT")
-	if endpoint == "" {
-		endpoint = "http://localhost:11434"
+// Supported values for PRBUDDY_LLM_PROVIDER.
+const (
+	ProviderOllama = "ollama"
+	ProviderOpenAI = "openai"
+)
+
+// GetLLMProvider returns the configured backend protocol, defaulting to Ollama.
+func GetLLMProvider() string {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("PRBUDDY_LLM_PROVIDER"))) {
+	case "openai", "openai-compatible", "vllm", "llamacpp", "llama.cpp":
+		return ProviderOpenAI
+	default:
+		return ProviderOllama
+	}
+}
+
+// newLLMClient returns the LLMClient implementation for the configured provider.
+func newLLMClient() LLMClient {
+	if GetLLMProvider() == ProviderOpenAI {
+		return &OpenAIClient{}
+	}
+	return &DefaultLLMClient{}
+}
+
+// GetLLMEndpoint returns PRBUDDY_LLM_ENDPOINT or the provider's usual local address.
+func GetLLMEndpoint() string {
+	if endpoint := os.Getenv("PRBUDDY_LLM_ENDPOINT"); endpoint != "" {
+		return endpoint
+	}
+	if GetLLMProvider() == ProviderOpenAI {
+		return "http://localhost:8080"
 	}
+	return "http://localhost:11434"
+}
+
+// probeTimeout bounds ProbeLLM: a server that is up lists its models at once.
+const probeTimeout = 2 * time.Second
+
+// ProbeLLM checks quickly that the LLM server answers, and picks its first model
+// when none is active, as GetLLMConfig would. Unlike GetLLMConfig it never starts
+// a model, so callers that must not block (git hooks) can give up at once.
+func ProbeLLM(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	endpoint := GetLLMEndpoint()
+	models, err := fetchModels(ctx, endpoint)
+	if err != nil {
+		return fmt.Errorf("LLM server at %s is unreachable: %w", endpoint, err)
+	}
+	if contextpkg.GetActiveModel() != "" {
+		return nil
+	}
+	for _, m := range models {
+		if name, _ := m["name"].(string); name != "" {
+			contextpkg.SetActiveModel(name)
+			return nil
+		}
+	}
+	// OpenAI-compatible servers accept requests without a model name
+	if GetLLMProvider() == ProviderOpenAI {
+		return nil
+	}
+	return fmt.Errorf("no models available on %s", endpoint)
+}
+
+// fetchModels lists the models available on the configured provider.
+func fetchModels(ctx context.Context, endpoint string) ([]map[string]interface{}, error) {
+	if GetLLMProvider() == ProviderOpenAI {
+		return fetchOpenAIModels(ctx, endpoint)
+	}
+	return fetchOllamaModels(ctx, endpoint)
+}
+
+func GetLLMConfig() (string, string) {
+	endpoint := GetLLMEndpoint()
 
 	model := contextpkg.GetActiveModel()
 	if model != "" {
@@ -535,7 +758,7 @@ func GetLLMConfig() (string, string) {
 	}
 
 	// Try to load available models via official endpoint
-	models, err := fetchOllamaModels(endpoint)
+	models, err := fetchModels(context.Background(), endpoint)
 	if err == nil && len(models) > 0 {
 		latest := models[0]
 		if name, ok := latest["name"].(string); ok {
@@ -544,11 +767,17 @@ func GetLLMConfig() (string, string) {
 		}
 	}
 
+	// OpenAI-compatible servers can't be asked to pull/run a model for us
+	if GetLLMProvider() == ProviderOpenAI {
+		logrus.Warnf("No models reported by %s; requests will be sent without a model name", endpoint)
+		return "", endpoint
+	}
+
 	// No models found — fallback to qwen3 and run it
 	logrus.Warn("No LLM model active or available; defaulting to 'qwen3'")
 
 	// Try to pre-warm the model with a dummy chat request
-	ready := tryEnsureModelReady(endpoint, "qwen3")
+	ready := tryEnsureModelReady(context.Background(), endpoint, "qwen3")
 	if !ready {
 		logrus.Warn("Attempting to start Ollama model 'qwen3' manually...")
 		cmd := exec.Command("ollama", "run", "qwen3")
@@ -556,17 +785,21 @@ func GetLLMConfig() (string, string) {
 		cmd.Stderr = os.Stderr
 		if err := cmd.Start(); err != nil {
 			logrus.Errorf("Failed to start Ollama: %v", err)
+		} else if !waitForModelReady(endpoint, "qwen3", modelReadyTimeout()) {
+			logrus.Warnf("Model 'qwen3' not ready after %s; continuing anyway", modelReadyTimeout())
 		}
-		// Crude wait; improve with polling if needed
-		time.Sleep(3 * time.Second)
 	}
 
 	contextpkg.SetActiveModel("qwen3")
 	return "qwen3", endpoint
 }
 
-func fetchOllamaModels(endpoint string) ([]map[string]interface{}, error) {
-	resp, err := http.Get(endpoint + "/api/tags")
+func fetchOllamaModels(ctx context.Context, endpoint string) ([]map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := discoveryClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to Ollama: %w", err)
 	}
@@ -585,8 +818,43 @@ func fetchOllamaModels(endpoint string) ([]map[string]interface{}, error) {
 	return result.Models, nil
 }
 
+// modelReadyTimeout bounds how long we poll for a freshly started model.
+// Cold loads of large models can take a while; override with PRBUDDY_MODEL_READY_TIMEOUT.
+func modelReadyTimeout() time.Duration {
+	if raw := os.Getenv("PRBUDDY_MODEL_READY_TIMEOUT"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			return d
+		}
+		logrus.Warnf("Ignoring invalid PRBUDDY_MODEL_READY_TIMEOUT=%q", raw)
+	}
+	return 2 * time.Minute
+}
+
+// waitForModelReady polls the model with readiness pings until it answers or timeout elapses.
+func waitForModelReady(endpoint, model string, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	interval := 500 * time.Millisecond
+	for {
+		if tryEnsureModelReady(ctx, endpoint, model) {
+			logrus.Infof("Model '%s' is ready", model)
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(interval):
+		}
+		if interval < 5*time.Second {
+			interval *= 2
+		}
+	}
+}
+
 // tryEnsureModelReady attempts to verify whether a model is loaded and available
-func tryEnsureModelReady(endpoint, model string) bool {
+func tryEnsureModelReady(ctx context.Context, endpoint, model string) bool {
 	payload := map[string]interface{}{
 		"model": model,
 		"messages": []map[string]string{
@@ -596,9 +864,17 @@ func tryEnsureModelReady(endpoint, model string) bool {
 	}
 	data, _ := json.Marshal(payload)
 
-	resp, err := http.Post(endpoint+"/api/chat", "application/json", bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint+"/api/chat", bytes.NewReader(data))
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		logrus.Warnf("Model readiness check failed: %v", err)
+		if ctx.Err() == nil {
+			logrus.Warnf("Model readiness check failed: %v", err)
+		}
 		return false
 	}
 	defer resp.Body.Close()
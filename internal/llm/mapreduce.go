@@ -0,0 +1,298 @@
+// internal/llm/mapreduce.go
+
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/prompts"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// Draft modes recorded alongside saved drafts.
+const (
+	DraftModeDirect    = "direct"     // the (possibly condensed) diff went straight into the draft prompt
+	DraftModeMapReduce = "map-reduce" // files were summarised first, the draft was written from the summaries
+	DraftModeRefined   = "refined"    // an earlier draft revised in conversation ('draft refine')
+	DraftModeEdited    = "edited"     // an earlier draft edited by hand ('pr create --edit')
+)
+
+// defaultMapReduceConcurrency bounds parallel summary requests; local servers
+// usually queue anything beyond a few concurrent generations anyway.
+const defaultMapReduceConcurrency = 3
+
+// DraftInput is what a PR draft is generated from. Diff is the full, untrimmed diff.
+// For branch-level drafts CommitMessage holds every commit message since the merge base.
+type DraftInput struct {
+	CommitMessage string
+	Diff          string
+
+	// Messages holds each commit message on its own, for Conventional Commits parsing.
+	// When empty CommitMessage is treated as the only message.
+	Messages []string
+
+	// Set for branch-level drafts only
+	Base      string
+	MergeBase string
+	Commits   int
+}
+
+// FileSummary is the LLM summary of one group of files from the map phase.
+type FileSummary struct {
+	Files   []string `json:"files"`
+	Summary string   `json:"summary"`
+}
+
+// DraftResult is a generated PR draft plus how it was produced.
+type DraftResult struct {
+	Draft         string           `json:"-"`
+	Structured    *StructuredDraft `json:"-"` // nil when the model couldn't produce valid JSON
+	Mode          string           `json:"mode"`
+	DiffTokens    int              `json:"diff_tokens"`
+	BudgetTokens  int              `json:"budget_tokens"`
+	FileSummaries []FileSummary    `json:"file_summaries,omitempty"`
+	Labels        []string         `json:"labels,omitempty"` // suggested from Conventional Commit types
+
+	// What produced the draft, so regenerations can be compared
+	Model          string `json:"model,omitempty"`
+	PromptTemplate string `json:"prompt_template,omitempty"`
+	PromptHash     string `json:"prompt_hash,omitempty"`
+	DraftTokens    int    `json:"draft_tokens"`
+}
+
+// LatestCommitDraftInput returns the message and full diff of HEAD.
+func LatestCommitDraftInput() (DraftInput, error) {
+	return CommitDraftInput("HEAD")
+}
+
+// CommitDraftInput returns the message and full diff of a single commit: against
+// its first parent, or against the empty tree for a root commit.
+func CommitDraftInput(rev string) (DraftInput, error) {
+	commitMsg, err := utils.ExecGit("log", "-1", "--pretty=%B", rev)
+	if err != nil {
+		return DraftInput{}, errors.Wrapf(err, "failed to get commit message of %s", rev)
+	}
+	diff, err := utils.ExecGit("diff-tree", "-p", "--root", "--no-commit-id", "--diff-merges=first-parent", rev)
+	if err != nil {
+		return DraftInput{}, errors.Wrapf(err, "failed to get git diff of %s", rev)
+	}
+	return DraftInput{CommitMessage: commitMsg, Diff: diff, Messages: []string{commitMsg}}, nil
+}
+
+// GenerateDraft writes a PR draft for in. When the diff fits the model's budget
+// (lockfiles and generated files aside) it is sent directly; otherwise each file
+// or group of files is summarised in parallel and the draft is written from those
+// summaries instead of from a diff with most hunks cut away.
+func GenerateDraft(ctx context.Context, in DraftInput) (*DraftResult, error) {
+	ctx, cancel := WithOperationTimeout(ctx, OpDraft)
+	defer cancel()
+
+	budget := diffTokenBudget(ctx, in.CommitMessage)
+	result := &DraftResult{
+		Mode:         DraftModeDirect,
+		DiffTokens:   contextpkg.EstimateTokens(in.Diff),
+		BudgetTokens: budget,
+	}
+
+	condensed := contextpkg.CondenseDiff(in.Diff, budget)
+	if !condensed.Lossy() {
+		data := promptData(condensed.Text)
+		data.CommitMessage, data.Base = in.CommitMessage, in.Base
+		if err := result.draft(ctx, data); err != nil {
+			return nil, err
+		}
+		result.applyConventions(in.messages())
+		return result, nil
+	}
+
+	logrus.Infof("[MapReduce] diff of ~%d tokens exceeds the ~%d token budget; summarising files first",
+		result.DiffTokens, budget)
+
+	summaries, err := summariseFileGroups(ctx, in.CommitMessage, groupDiffFiles(condensed.Files, budget))
+	if err != nil {
+		return nil, err
+	}
+	result.Mode = DraftModeMapReduce
+	result.FileSummaries = summaries
+
+	changes := contextpkg.TruncateToTokens(renderFileSummaries(condensed, summaries), budget)
+	// The summaries stand in for the diff; its files are already parsed in condensed
+	data := promptData(changes)
+	data.CommitMessage, data.Base = in.CommitMessage, in.Base
+	data.DiffHeading, data.Files = "Summarised Code Changes", groupPaths(condensed.Files)
+	if err := result.draft(ctx, data); err != nil {
+		return nil, err
+	}
+	result.applyConventions(in.messages())
+	return result, nil
+}
+
+// draft fills in the draft from data, preferring a structured JSON draft and falling
+// back to the free-form markdown prompt when the model can't produce valid JSON.
+func (r *DraftResult) draft(ctx context.Context, data prompts.Data) error {
+	structured, err := generateStructuredDraft(ctx, data)
+	if err == nil {
+		r.Structured = structured
+		r.Draft = structured.Markdown()
+		r.setPrompt(prompts.DraftJSON)
+		return nil
+	}
+	if ctx.Err() != nil {
+		return err
+	}
+	logrus.Warnf("Falling back to a markdown draft: %v", err)
+
+	draft, err := generateDraftFromChanges(ctx, data)
+	if err != nil {
+		return err
+	}
+	r.Draft = draft
+	r.setPrompt(prompts.Draft)
+	return nil
+}
+
+// setPrompt records which template (and which version of it) the draft came from.
+func (r *DraftResult) setPrompt(name string) {
+	r.PromptTemplate, r.PromptHash = name, prompts.Hash(name)
+	r.DraftTokens = contextpkg.EstimateTokens(r.Draft)
+	// Known by now: the first request resolves the model when none was active
+	r.Model = contextpkg.GetActiveModel()
+}
+
+// groupDiffFiles packs consecutive non-noise files into groups of at most maxTokens.
+// A file that alone exceeds maxTokens forms its own group and is condensed later.
+func groupDiffFiles(files []contextpkg.DiffFile, maxTokens int) [][]contextpkg.DiffFile {
+	var groups [][]contextpkg.DiffFile
+	var current []contextpkg.DiffFile
+	used := 0
+
+	for _, f := range files {
+		if contextpkg.NoiseReason(f.Path) != "" {
+			continue
+		}
+		cost := contextpkg.EstimateTokens(f.String())
+		if len(current) > 0 && used+cost > maxTokens {
+			groups = append(groups, current)
+			current, used = nil, 0
+		}
+		current = append(current, f)
+		used += cost
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+// summariseFileGroups runs the map phase with at most PRBUDDY_MAPREDUCE_CONCURRENCY
+// requests in flight. The first failure cancels the remaining requests.
+func summariseFileGroups(ctx context.Context, commitMessage string, groups [][]contextpkg.DiffFile) ([]FileSummary, error) {
+	concurrency := envInt("PRBUDDY_MAPREDUCE_CONCURRENCY")
+	if concurrency <= 0 {
+		concurrency = defaultMapReduceConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	summaries := make([]FileSummary, len(groups))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for i, group := range groups {
+		wg.Add(1)
+		go func(i int, group []contextpkg.DiffFile) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			summary, err := summariseFileGroup(ctx, commitMessage, group)
+			if err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("summarising %s: %w", groupLabel(group), err)
+					cancel()
+				})
+				return
+			}
+			summaries[i] = FileSummary{Files: groupPaths(group), Summary: summary}
+			logrus.Debugf("[MapReduce] summarised %d/%d: %s", i+1, len(groups), groupLabel(group))
+		}(i, group)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// summariseFileGroup asks the LLM for a short factual summary of one group's diff.
+func summariseFileGroup(ctx context.Context, commitMessage string, group []contextpkg.DiffFile) (string, error) {
+	var diff strings.Builder
+	for _, f := range group {
+		diff.WriteString(f.String())
+	}
+	maxTokens := diffTokenBudget(ctx, commitMessage)
+	text := contextpkg.CondenseDiff(diff.String(), maxTokens).Text
+
+	prompt, err := prompts.Render(prompts.Summary, prompts.Data{
+		CommitMessage: commitMessage,
+		Diff:          text,
+		DiffHeading:   "Code Changes",
+		Files:         groupPaths(group),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	messages := []contextpkg.Message{
+		{Role: "system", Content: "You are a helpful assistant."},
+		{Role: "user", Content: prompt},
+	}
+	response, err := llmClient.GetChatResponse(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(utils.StripOuterMarkdownCodeFence(response)), nil
+}
+
+// renderFileSummaries is the reduce-phase input: the stat header plus every summary.
+func renderFileSummaries(condensed contextpkg.CondensedDiff, summaries []FileSummary) string {
+	var b strings.Builder
+	b.WriteString("The diff was too large to include; each group of files was summarised separately.\n\n")
+	b.WriteString(contextpkg.DiffStatHeader(condensed.Files))
+	for _, s := range summaries {
+		b.WriteString(fmt.Sprintf("\n### %s\n%s\n", strings.Join(s.Files, ", "), s.Summary))
+	}
+	return b.String()
+}
+
+func groupPaths(group []contextpkg.DiffFile) []string {
+	paths := make([]string, len(group))
+	for i, f := range group {
+		paths[i] = f.Path
+	}
+	return paths
+}
+
+func groupLabel(group []contextpkg.DiffFile) string {
+	if len(group) == 1 {
+		return group[0].Path
+	}
+	return fmt.Sprintf("%s and %d more file(s)", group[0].Path, len(group)-1)
+}
@@ -2,7 +2,9 @@
 package llm
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,36 +12,37 @@ import (
 	"github.com/soyuz43/prbuddy-go/internal/utils"
 )
 
-// JSONHandler creates a handler for JSON requests/responses with unified error handling
-func JSONHandler[T any](logic func(T) (any, error)) http.HandlerFunc {
+// JSONHandler creates a handler for JSON requests/responses with unified error handling.
+// The request context is passed through so a disconnected client cancels LLM work.
+func JSONHandler[T any](logic func(context.Context, T) (any, error)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Set content type first
 		w.Header().Set("Content-Type", "application/json")
 
 		// Validate HTTP method
 		if r.Method != http.MethodPost {
-			writeError(w, fmt.Sprintf("Method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+			writeError(w, methodNotAllowed(r.Method))
 			return
 		}
 
 		// Decode request
 		var req T
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			writeError(w, "Invalid request format", http.StatusBadRequest)
+			writeError(w, invalidRequest("Invalid request format"))
 			return
 		}
 
 		// Execute handler logic
-		response, err := logic(req)
+		response, err := logic(r.Context(), req)
 		if err != nil {
-			writeError(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 
 		// Marshal response
 		jsonResponse, err := utils.MarshalJSON(response)
 		if err != nil {
-			writeError(w, "Failed to marshal response", http.StatusInternalServerError)
+			writeError(w, newAPIError(http.StatusInternalServerError, CodeInternal, "Failed to marshal response"))
 			return
 		}
 
@@ -48,12 +51,71 @@ func JSONHandler[T any](logic func(T) (any, error)) http.HandlerFunc {
 	}
 }
 
+// Stable error codes, sent in the "code" field of every error response. Clients
+// should branch on these rather than on messages, which may change.
+const (
+	CodeInvalidRequest   = "invalid_request"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeCancelled        = "request_cancelled"
+	CodeMisdirected      = "misdirected_request"
+	CodeLLMError         = "llm_error"
+	CodeTimeout          = "timeout"
+	CodeInternal         = "internal_error"
+)
+
+// APIError is an error reported to the client with its HTTP status; it is also
+// the body of every error response. "error" keeps the message where clients of
+// the unversioned routes expect it.
+type APIError struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"error"`
+}
+
+func (e *APIError) Error() string { return e.Message }
+
+func newAPIError(status int, code, format string, args ...any) *APIError {
+	return &APIError{Status: status, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func invalidRequest(format string, args ...any) *APIError {
+	return newAPIError(http.StatusBadRequest, CodeInvalidRequest, format, args...)
+}
+
+func methodNotAllowed(method string) *APIError {
+	return newAPIError(http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method %s not allowed", method)
+}
+
+// toAPIError classifies err: deadline and cancellation errors and LLM failures
+// get distinct statuses, anything unexpected is an internal error.
+func toAPIError(err error) *APIError {
+	var apiErr *APIError
+	var statusErr *StatusError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr
+	case errors.Is(err, context.DeadlineExceeded):
+		return newAPIError(http.StatusGatewayTimeout, CodeTimeout, "%s", err.Error())
+	case errors.Is(err, context.Canceled):
+		// Client went away; nobody will read this, but keep the log line meaningful
+		return newAPIError(http.StatusRequestTimeout, CodeCancelled, "%s", err.Error())
+	case errors.As(err, &statusErr):
+		return newAPIError(http.StatusBadGateway, CodeLLMError, "%s", err.Error())
+	default:
+		return newAPIError(http.StatusInternalServerError, CodeInternal, "%s", err.Error())
+	}
+}
+
 // writeError handles error responses consistently
-func writeError(w http.ResponseWriter, message string, code int) {
-	log.Printf("HTTP %d: %s", code, message)
-	w.WriteHeader(code)
-	errorResponse := map[string]string{"error": message}
-	if jsonErr, err := utils.MarshalJSON(errorResponse); err == nil {
+func writeError(w http.ResponseWriter, err error) {
+	apiErr := toAPIError(err)
+	log.Printf("HTTP %d: %s", apiErr.Status, apiErr.Message)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.Status)
+	if jsonErr, err := utils.MarshalJSON(apiErr); err == nil {
 		w.Write([]byte(jsonErr))
 	}
 }
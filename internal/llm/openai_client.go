@@ -0,0 +1,262 @@
+// internal/llm/openai_client.go
+
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+)
+
+//------------------------------------------------------------------------------
+// OPENAI-COMPATIBLE IMPLEMENTATION (llama.cpp server, vLLM, ...)
+//------------------------------------------------------------------------------
+
+// OpenAIClient implements the LLMClient interface using the OpenAI-compatible
+// /v1/chat/completions endpoint.
+type OpenAIClient struct{}
+
+// openAIMessage is the minimal message shape accepted by OpenAI-compatible servers.
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// OpenAIChatResponse represents a non-streaming /v1/chat/completions response.
+type OpenAIChatResponse struct {
+	Choices []struct {
+		Message struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+// OpenAIStreamChunk is a single SSE "data:" payload during streaming.
+type OpenAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Role    string `json:"role,omitempty"`
+			Content string `json:"content,omitempty"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+func toOpenAIMessages(messages []contextpkg.Message) []openAIMessage {
+	out := make([]openAIMessage, 0, len(messages))
+	for _, m := range messages {
+		out = append(out, openAIMessage{Role: m.Role, Content: m.Content})
+	}
+	return out
+}
+
+// newOpenAIRequest builds a POST request to /v1/chat/completions with auth headers set.
+func newOpenAIRequest(ctx context.Context, endpoint string, body map[string]interface{}) (*http.Request, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", openAIBaseURL(endpoint)+"/v1/chat/completions", bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setOpenAIAuth(req)
+	return req, nil
+}
+
+//------------------------------------------------------------------------------
+// NON-STREAMING METHOD: GetChatResponse
+//------------------------------------------------------------------------------
+
+func (c *OpenAIClient) GetChatResponse(ctx context.Context, messages []contextpkg.Message) (string, error) {
+	return c.chat(ctx, messages, nil)
+}
+
+// GetStructuredResponse requests a "json_schema" response_format, which vLLM and
+// llama.cpp enforce with grammar-constrained decoding.
+func (c *OpenAIClient) GetStructuredResponse(ctx context.Context, messages []contextpkg.Message, schema map[string]interface{}) (string, error) {
+	return c.chat(ctx, messages, schema)
+}
+
+func (c *OpenAIClient) chat(ctx context.Context, messages []contextpkg.Message, schema map[string]interface{}) (string, error) {
+	model, endpoint := GetLLMConfig()
+
+	body := map[string]interface{}{
+		"model":    model,
+		"messages": toOpenAIMessages(messages),
+		"stream":   false,
+	}
+	if schema != nil {
+		body["response_format"] = map[string]interface{}{
+			"type":        "json_schema",
+			"json_schema": map[string]interface{}{"name": "response", "schema": schema},
+		}
+	}
+
+	req, err := newOpenAIRequest(ctx, endpoint, body)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to send POST request to LLM")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	var llmResp OpenAIChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&llmResp); err != nil {
+		return "", errors.Wrap(err, "failed to decode LLM response")
+	}
+
+	if len(llmResp.Choices) == 0 || llmResp.Choices[0].Message.Content == "" {
+		return "", fmt.Errorf("empty response from LLM")
+	}
+
+	logrus.Info("Received response from LLM successfully (non-stream).")
+	return llmResp.Choices[0].Message.Content, nil
+}
+
+//------------------------------------------------------------------------------
+// STREAMING METHOD: StreamChatResponse
+//------------------------------------------------------------------------------
+
+// StreamChatResponse reads Server-Sent Events from /v1/chat/completions.
+// Each event is a "data: {json}" line; the stream ends with "data: [DONE]".
+func (c *OpenAIClient) StreamChatResponse(ctx context.Context, messages []contextpkg.Message) (<-chan string, error) {
+	model, endpoint := GetLLMConfig()
+
+	req, err := newOpenAIRequest(ctx, endpoint, map[string]interface{}{
+		"model":    model,
+		"messages": toOpenAIMessages(messages),
+		"stream":   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	outChan := make(chan string)
+
+	go func() {
+		defer resp.Body.Close()
+		defer close(outChan)
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+
+			// Skip blank separators, comments and non-data fields (event:, id:, retry:)
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if data == "[DONE]" {
+				break
+			}
+
+			var chunk OpenAIStreamChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				// Log parse errors but keep going
+				logrus.Errorf("Failed to unmarshal streaming chunk: %v", err)
+				continue
+			}
+
+			for _, choice := range chunk.Choices {
+				if choice.Delta.Content == "" {
+					continue
+				}
+				select {
+				case outChan <- choice.Delta.Content:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			logrus.Errorf("Scanner error while reading streaming response: %v", err)
+		}
+	}()
+
+	return outChan, nil
+}
+
+//------------------------------------------------------------------------------
+// MODEL DISCOVERY + HELPERS
+//------------------------------------------------------------------------------
+
+// fetchOpenAIModels lists models from /v1/models, normalised to the same
+// shape fetchOllamaModels returns (each entry carries a "name" key).
+func fetchOpenAIModels(ctx context.Context, endpoint string) ([]map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", openAIBaseURL(endpoint)+"/v1/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	setOpenAIAuth(req)
+
+	resp, err := discoveryClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LLM server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("LLM server returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for _, m := range result.Data {
+		if id, ok := m["id"].(string); ok {
+			m["name"] = id
+		}
+	}
+	return result.Data, nil
+}
+
+// openAIBaseURL strips a trailing slash and "/v1" so both
+// "http://host:8080" and "http://host:8080/v1" are accepted as endpoints.
+func openAIBaseURL(endpoint string) string {
+	base := strings.TrimRight(endpoint, "/")
+	return strings.TrimSuffix(base, "/v1")
+}
+
+// setOpenAIAuth adds a bearer token when PRBUDDY_LLM_API_KEY is set (vLLM --api-key, llama.cpp --api-key).
+func setOpenAIAuth(req *http.Request) {
+	if key := os.Getenv("PRBUDDY_LLM_API_KEY"); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+}
@@ -0,0 +1,193 @@
+// internal/llm/openapi.go
+
+package llm
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var pathParamRe = regexp.MustCompile(`\{([^}]+)\}`)
+
+// openAPIDocument describes routes as an OpenAPI 3 document, with the schemas of
+// their request and response types derived by reflection.
+func openAPIDocument(routes []apiRoute) map[string]any {
+	schemas := &schemaSet{components: map[string]any{
+		"APIError": map[string]any{
+			"type":     "object",
+			"required": []string{"code", "error"},
+			"properties": map[string]any{
+				"code": map[string]any{"type": "string", "enum": []string{
+					CodeInvalidRequest, CodeUnauthorized, CodeForbidden, CodeNotFound, CodeMethodNotAllowed,
+					CodeCancelled, CodeMisdirected, CodeLLMError, CodeTimeout, CodeInternal,
+				}},
+				"error": map[string]any{"type": "string"},
+			},
+		},
+	}}
+	errorResponse := map[string]any{
+		"description": "Error",
+		"content": map[string]any{
+			"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/APIError"}},
+		},
+	}
+
+	paths := make(map[string]any)
+	for _, route := range routes {
+		op := map[string]any{
+			"summary":     route.Summary,
+			"operationId": operationID(route),
+		}
+
+		var params []any
+		for _, m := range pathParamRe.FindAllStringSubmatch(route.Path, -1) {
+			params = append(params, map[string]any{
+				"name": m[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+			})
+		}
+		if route.Request != nil {
+			switch route.Method {
+			case http.MethodGet, http.MethodDelete:
+				t := reflect.TypeOf(route.Request)
+				for i := 0; i < t.NumField(); i++ {
+					if name := jsonName(t.Field(i)); name != "" {
+						params = append(params, map[string]any{
+							"name": name, "in": "query", "required": isRequired(t.Field(i)),
+							"schema": schemas.schema(t.Field(i).Type),
+						})
+					}
+				}
+			default:
+				op["requestBody"] = map[string]any{
+					"required": true,
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": schemas.schema(reflect.TypeOf(route.Request))},
+					},
+				}
+			}
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+
+		ok := map[string]any{"description": "OK"}
+		switch {
+		case route.Stream:
+			ok["description"] = `Server-Sent Events: "chunk" events ({"content": "..."}), then "done" ({"conversationId": "..."}) or "error" (APIError)`
+			ok["content"] = map[string]any{"text/event-stream": map[string]any{"schema": map[string]any{"type": "string"}}}
+		case route.Response != nil:
+			content := map[string]any{
+				"application/json": map[string]any{"schema": schemas.schema(reflect.TypeOf(route.Response))},
+			}
+			if route.Produces != "" {
+				content[route.Produces] = map[string]any{"schema": map[string]any{"type": "string"}}
+			}
+			ok["content"] = content
+		}
+		op["responses"] = map[string]any{"200": ok, "default": errorResponse}
+
+		path := route.Path
+		if paths[path] == nil {
+			paths[path] = make(map[string]any)
+		}
+		paths[path].(map[string]any)[strings.ToLower(route.Method)] = op
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "PRBuddy-Go API",
+			"version":     version(),
+			"description": "Local API of 'prbuddy-go serve'. The port and bearer token are in the user cache directory (prbuddy-go/port and prbuddy-go/token).",
+		},
+		"servers":  []any{map[string]any{"url": "http://localhost:{port}", "variables": map[string]any{"port": map[string]any{"default": "0"}}}},
+		"security": []any{map[string]any{"bearerAuth": []string{}}},
+		"paths":    paths,
+		"components": map[string]any{
+			"securitySchemes": map[string]any{"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"}},
+			"schemas":         schemas.components,
+		},
+	}
+}
+
+// operationID names an operation after its method and path, e.g. getV1Info.
+func operationID(route apiRoute) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(route.Method))
+	for _, part := range strings.FieldsFunc(route.Path, func(r rune) bool {
+		return r == '/' || r == '.' || r == '{' || r == '}' || r == '_' || r == '-'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// schemaSet builds JSON schemas, collecting named struct types as components.
+type schemaSet struct {
+	components map[string]any
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (s *schemaSet) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.String:
+		return map[string]any{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]any{"type": "number"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]any{"type": "array", "items": s.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.schema(t.Elem())}
+	case t.Kind() == reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		if _, ok := s.components[t.Name()]; !ok {
+			// Placeholder first, for types that refer to themselves
+			s.components[t.Name()] = map[string]any{}
+			s.components[t.Name()] = s.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	// interface{} and anything else: any JSON value
+	return map[string]any{}
+}
+
+func (s *schemaSet) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+		if name == "" {
+			continue
+		}
+		properties[name] = s.schema(field.Type)
+		if isRequired(field) {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// isRequired treats fields without omitempty as required.
+func isRequired(field reflect.StructField) bool {
+	return !strings.Contains(field.Tag.Get("json"), ",omitempty")
+}
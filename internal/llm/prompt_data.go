@@ -0,0 +1,50 @@
+// internal/llm/prompt_data.go
+
+package llm
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/prompts"
+	"github.com/soyuz43/prbuddy-go/internal/prtemplate"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// promptData fills the template variables shared by every prompt. Branch lookup
+// failures (detached HEAD, no repo) simply leave Branch empty.
+func promptData(diff string) prompts.Data {
+	data := prompts.Data{Diff: diff, DiffHeading: "Code Changes"}
+	if branch, err := utils.GetCurrentBranch(); err == nil && branch != "HEAD" {
+		data.Branch = strings.TrimSpace(branch)
+	}
+	data.Files = diffPaths(diff)
+	return data
+}
+
+// diffPaths lists the files touched by a unified diff.
+func diffPaths(diff string) []string {
+	files, _ := contextpkg.ParseUnifiedDiff(diff)
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
+// withPRTemplate adds the repository's pull request template to data, if there is one,
+// and returns it so the draft can be checked against its headings.
+func withPRTemplate(data *prompts.Data) *prtemplate.Template {
+	tmpl, err := prtemplate.Find()
+	if err != nil {
+		logrus.Warnf("Ignoring pull request template: %v", err)
+		return nil
+	}
+	if tmpl == nil {
+		return nil
+	}
+	data.PRTemplate = strings.TrimSpace(tmpl.Content)
+	data.RequiredHeadings = tmpl.Headings
+	return tmpl
+}
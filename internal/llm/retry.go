@@ -0,0 +1,200 @@
+// internal/llm/retry.go
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+)
+
+//------------------------------------------------------------------------------
+// RETRY POLICY
+//------------------------------------------------------------------------------
+
+// RetryPolicy controls how transient LLM failures are retried.
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first one
+	BaseDelay   time.Duration // Backoff ceiling for the first retry
+	MaxDelay    time.Duration // Upper bound for any single backoff
+}
+
+// DefaultRetryPolicy returns the policy used by the global client.
+// PRBUDDY_LLM_MAX_ATTEMPTS overrides the attempt count (1 disables retries).
+func DefaultRetryPolicy() RetryPolicy {
+	policy := RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    8 * time.Second,
+	}
+	if raw := os.Getenv("PRBUDDY_LLM_MAX_ATTEMPTS"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			policy.MaxAttempts = n
+		} else {
+			logrus.Warnf("Ignoring invalid PRBUDDY_LLM_MAX_ATTEMPTS=%q", raw)
+		}
+	}
+	return policy
+}
+
+// Backoff returns a "full jitter" delay for the given retry (0-based):
+// a random duration in [0, min(MaxDelay, BaseDelay*2^retry)].
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	ceiling := p.BaseDelay << uint(retry)
+	if ceiling <= 0 || ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+type failFastKey struct{}
+
+// WithFailFast marks ctx for callers that must not wait on an unreachable LLM
+// server, such as git hooks: connection failures are returned instead of retried.
+func WithFailFast(ctx context.Context) context.Context {
+	return context.WithValue(ctx, failFastKey{}, true)
+}
+
+func isFailFast(ctx context.Context) bool {
+	failFast, _ := ctx.Value(failFastKey{}).(bool)
+	return failFast
+}
+
+// isConnectionError reports whether err means the server could not be reached at all.
+func isConnectionError(err error) bool {
+	var opErr *net.OpError
+	return errors.Is(err, syscall.ECONNREFUSED) || (errors.As(err, &opErr) && opErr.Op == "dial")
+}
+
+// Do runs fn until it succeeds, returns a non-retryable error, the attempts are
+// exhausted, or ctx is done. The last error is returned. Under WithFailFast,
+// connection failures aren't retried.
+func (p RetryPolicy) Do(ctx context.Context, op string, fn func() error) error {
+	attempts := p.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || !IsRetryable(err) || attempt == attempts || (isFailFast(ctx) && isConnectionError(err)) {
+			break
+		}
+
+		delay := p.Backoff(attempt - 1)
+		logrus.Warnf("%s failed (attempt %d/%d): %v; retrying in %s", op, attempt, attempts, err, delay.Round(time.Millisecond))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (gave up waiting to retry: %v)", err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+//------------------------------------------------------------------------------
+// ERROR CLASSIFICATION
+//------------------------------------------------------------------------------
+
+// StatusError reports a non-200 response from the LLM server.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("LLM responded with status code %d", e.StatusCode)
+}
+
+// IsRetryable reports whether err looks transient: overload/restart status codes,
+// refused or reset connections, and truncated responses. Context errors never are.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests,
+			http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+//------------------------------------------------------------------------------
+// RETRYING CLIENT
+//------------------------------------------------------------------------------
+
+// RetryingClient wraps another LLMClient and retries transient failures.
+// Streaming calls are only retried while establishing the stream; once chunks
+// have been delivered the stream is never replayed.
+type RetryingClient struct {
+	inner  LLMClient
+	policy RetryPolicy
+}
+
+// NewRetryingClient wraps inner with the given retry policy.
+func NewRetryingClient(inner LLMClient, policy RetryPolicy) *RetryingClient {
+	return &RetryingClient{inner: inner, policy: policy}
+}
+
+func (c *RetryingClient) GetChatResponse(ctx context.Context, messages []contextpkg.Message) (string, error) {
+	var response string
+	err := c.policy.Do(ctx, "LLM chat request", func() error {
+		var err error
+		response, err = c.inner.GetChatResponse(ctx, messages)
+		return err
+	})
+	return response, err
+}
+
+func (c *RetryingClient) GetStructuredResponse(ctx context.Context, messages []contextpkg.Message, schema map[string]interface{}) (string, error) {
+	var response string
+	err := c.policy.Do(ctx, "LLM structured request", func() error {
+		var err error
+		response, err = structuredResponse(ctx, c.inner, messages, schema)
+		return err
+	})
+	return response, err
+}
+
+func (c *RetryingClient) StreamChatResponse(ctx context.Context, messages []contextpkg.Message) (<-chan string, error) {
+	var stream <-chan string
+	err := c.policy.Do(ctx, "LLM stream request", func() error {
+		var err error
+		stream, err = c.inner.StreamChatResponse(ctx, messages)
+		return err
+	})
+	return stream, err
+}
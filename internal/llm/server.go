@@ -28,6 +28,7 @@ const (
 type ServerConfig struct {
 	Host              string
 	InactivityTimeout time.Duration
+	Janitor           JanitorConfig
 }
 
 // StartServer initializes and runs the HTTP server with full lifecycle management
@@ -41,34 +42,59 @@ func StartServer(cfg ServerConfig) error {
 		return fmt.Errorf("failed to create listener: %w", err)
 	}
 
+	// Clients read the token next to the port file; it's written first so a
+	// client that finds the new port also finds its token
+	token, err := NewSessionToken()
+	if err != nil {
+		return err
+	}
+	if err := utils.WriteTokenFile(token); err != nil {
+		return fmt.Errorf("token file write failed: %w", err)
+	}
+
 	port := listener.Addr().(*net.TCPAddr).Port
 	if err := utils.WritePortFile(port); err != nil {
 		return fmt.Errorf("port file write failed: %w", err)
 	}
 
-	router := http.NewServeMux()
-	registerHandlers(router)
-
+	serverStartedAt = time.Now()
 	server := &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", cfg.Host, port),
-		Handler: router,
+		Addr: fmt.Sprintf("%s:%d", cfg.Host, port),
+		Handler: RequireAuth(AuthConfig{
+			Token:          token,
+			Port:           port,
+			AllowedOrigins: AllowedOriginsFromEnv(),
+		}, NewRouter()),
 	}
 
-	return manageServerLifecycle(server, listener, cfg.InactivityTimeout)
+	janitor := StartJanitor(contextpkg.ConversationManagerInstance, cfg.Janitor)
+	return manageServerLifecycle(server, listener, janitor, cfg.InactivityTimeout)
+}
+
+// NewRouter returns the server's routes: the versioned API under /v1/ and the
+// original unversioned routes, kept for older clients.
+func NewRouter() *http.ServeMux {
+	router := http.NewServeMux()
+	registerAPI(router)
+	registerHandlers(router)
+	return router
 }
 
 func registerHandlers(router *http.ServeMux) {
 	router.HandleFunc("/quickassist", quickAssistHandler())
+	router.HandleFunc("/quickassist/stream", quickAssistStreamHandler())
 	router.HandleFunc("/dce", dceHandler())
+	router.HandleFunc("/dce/stream", dceStreamHandler())
 	router.HandleFunc("/quickassist/clear", quickAssistClearHandler())
 	router.HandleFunc("/extension/drafts", saveDraftHandler())
 	router.HandleFunc("/extension/drafts/load", loadDraftHandler())
 	router.HandleFunc("/what", whatHandler())
+	router.HandleFunc("/what/stream", whatStreamHandler())
 	router.HandleFunc("/extension/models", listModelsHandler())
 	router.HandleFunc("/extension/model", setModelHandler())
 }
 
-func manageServerLifecycle(server *http.Server, listener net.Listener, timeout time.Duration) error {
+func manageServerLifecycle(server *http.Server, listener net.Listener, janitor *Janitor, timeout time.Duration) error {
 	shutdownChan := make(chan os.Signal, 1)
 	signal.Notify(shutdownChan, os.Interrupt, syscall.SIGTERM)
 
@@ -90,11 +116,14 @@ func manageServerLifecycle(server *http.Server, listener net.Listener, timeout t
 	}()
 
 	fmt.Printf("Server listening on %s\n", listener.Addr())
-	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+	err := server.Serve(listener)
+	janitor.Stop()
+	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("server error: %w", err)
 	}
 
 	_ = utils.DeletePortFile()
+	_ = utils.DeleteTokenFile()
 	fmt.Println("Server shutdown completed successfully")
 	return nil
 }
@@ -107,6 +136,7 @@ var ServeCmd = &cobra.Command{
 		cfg := ServerConfig{
 			Host:              defaultHost,
 			InactivityTimeout: defaultInactivityTimeout,
+			Janitor:           JanitorConfigFromEnv(),
 		}
 
 		if err := StartServer(cfg); err != nil {
@@ -119,12 +149,12 @@ var ServeCmd = &cobra.Command{
 // Request/Response types
 type (
 	QuickAssistRequest struct {
-		ConversationID string `json:"conversationId"`
+		ConversationID string `json:"conversationId,omitempty"`
 		Input          string `json:"input"`
 	}
 
 	DCERequest struct {
-		ConversationID string `json:"conversationId"`
+		ConversationID string `json:"conversationId,omitempty"`
 		Input          string `json:"input"`
 	}
 
@@ -150,21 +180,37 @@ type (
 
 // Handlers
 func quickAssistHandler() http.HandlerFunc {
-	return JSONHandler(func(req QuickAssistRequest) (any, error) {
-		return HandleQuickAssist(req.ConversationID, req.Input)
+	return JSONHandler(func(ctx context.Context, req QuickAssistRequest) (any, error) {
+		return HandleQuickAssist(ctx, req.ConversationID, req.Input)
 	})
 }
 
 func dceHandler() http.HandlerFunc {
-	return JSONHandler(func(req DCERequest) (any, error) {
-		return HandleDCERequest(req.ConversationID, req.Input)
+	return JSONHandler(func(ctx context.Context, req DCERequest) (any, error) {
+		return HandleDCERequest(ctx, req.ConversationID, req.Input)
+	})
+}
+
+// Streaming variants: the answer arrives as "chunk" events, then a "done" event
+// with the conversation ID to continue with
+func quickAssistStreamHandler() http.HandlerFunc {
+	return SSEHandler(func(ctx context.Context, req QuickAssistRequest, onChunk ChunkFunc) (string, error) {
+		id, _, err := StreamQuickAssist(ctx, req.ConversationID, req.Input, onChunk)
+		return id, err
+	})
+}
+
+func dceStreamHandler() http.HandlerFunc {
+	return SSEHandler(func(ctx context.Context, req DCERequest, onChunk ChunkFunc) (string, error) {
+		id, _, err := StreamDCERequest(ctx, req.ConversationID, req.Input, onChunk)
+		return id, err
 	})
 }
 
 func quickAssistClearHandler() http.HandlerFunc {
-	return JSONHandler(func(req ClearRequest) (any, error) {
+	return JSONHandler(func(_ context.Context, req ClearRequest) (any, error) {
 		if req.ConversationID == "" {
-			return nil, fmt.Errorf("conversationId is required")
+			return nil, invalidRequest("conversationId is required")
 		}
 		contextpkg.ConversationManagerInstance.RemoveConversation(req.ConversationID)
 		return map[string]string{"status": "cleared"}, nil
@@ -172,12 +218,12 @@ func quickAssistClearHandler() http.HandlerFunc {
 }
 
 func saveDraftHandler() http.HandlerFunc {
-	return JSONHandler(func(req DraftSaveRequest) (any, error) {
+	return JSONHandler(func(_ context.Context, req DraftSaveRequest) (any, error) {
 		if req.Branch == "" || req.Commit == "" {
-			return nil, fmt.Errorf("branch and commit are required")
+			return nil, invalidRequest("branch and commit are required")
 		}
 		if len(req.Messages) == 0 {
-			return nil, fmt.Errorf("messages are required")
+			return nil, invalidRequest("messages are required")
 		}
 		if err := SaveDraftContext(req.Branch, req.Commit, req.Messages); err != nil {
 			return nil, err
@@ -187,36 +233,39 @@ func saveDraftHandler() http.HandlerFunc {
 }
 
 func loadDraftHandler() http.HandlerFunc {
-	return JSONHandler(func(req DraftLoadRequest) (any, error) {
-		context, err := LoadDraftContext(req.Branch, req.Commit)
+	return JSONHandler(func(_ context.Context, req DraftLoadRequest) (any, error) {
+		messages, err := LoadDraftContext(req.Branch, req.Commit)
 		if err != nil {
 			return nil, err
 		}
-		return map[string]interface{}{"status": "success", "messages": context}, nil
+		return map[string]interface{}{"status": "success", "messages": messages}, nil
 	})
 }
 
 func whatHandler() http.HandlerFunc {
-	return JSONHandler(func(_ struct{}) (any, error) {
-		summary, err := GenerateWhatSummary()
+	return JSONHandler(func(ctx context.Context, _ struct{}) (any, error) {
+		summary, err := GenerateWhatSummary(ctx)
 		return map[string]string{"summary": summary}, err
 	})
 }
 
+func whatStreamHandler() http.HandlerFunc {
+	return SSEHandler(func(ctx context.Context, _ struct{}, onChunk ChunkFunc) (string, error) {
+		_, err := StreamWhatSummary(ctx, onChunk)
+		return "", err
+	})
+}
+
 func listModelsHandler() http.HandlerFunc {
-	return JSONHandler(func(_ struct{}) (any, error) {
-		endpoint := os.Getenv("PRBUDDY_LLM_ENDPOINT")
-		if endpoint == "" {
-			endpoint = "http://localhost:11434"
-		}
-		return fetchOllamaModels(endpoint)
+	return JSONHandler(func(ctx context.Context, _ struct{}) (any, error) {
+		return fetchModels(ctx, GetLLMEndpoint())
 	})
 }
 
 func setModelHandler() http.HandlerFunc {
-	return JSONHandler(func(req ModelRequest) (any, error) {
+	return JSONHandler(func(_ context.Context, req ModelRequest) (any, error) {
 		if req.Model == "" {
-			return nil, fmt.Errorf("missing 'model' field")
+			return nil, invalidRequest("missing 'model' field")
 		}
 		contextpkg.SetActiveModel(req.Model)
 		return map[string]string{
@@ -0,0 +1,139 @@
+// internal/llm/stream.go
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+)
+
+// ChunkFunc receives each piece of a response as the model produces it.
+// Returning an error stops generation.
+type ChunkFunc func(chunk string) error
+
+const noChangesSummary = "No changes detected since the last commit."
+
+// respond returns the model's answer to messages: in one piece when onChunk is
+// nil, otherwise streamed through onChunk.
+func respond(ctx context.Context, messages []contextpkg.Message, onChunk ChunkFunc) (string, error) {
+	if onChunk == nil {
+		return llmClient.GetChatResponse(ctx, messages)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := llmClient.StreamChatResponse(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+	return collectStream(ctx, stream, onChunk)
+}
+
+// respondWith answers with a fixed text, passing it to onChunk as a single chunk.
+func respondWith(text string, onChunk ChunkFunc) (string, error) {
+	if onChunk != nil {
+		if err := onChunk(text); err != nil {
+			return "", err
+		}
+	}
+	return text, nil
+}
+
+// collectStream joins the chunks of stream, passing each to onChunk when set.
+// When onChunk fails the caller must cancel ctx, which stops the stream.
+func collectStream(ctx context.Context, stream <-chan string, onChunk ChunkFunc) (string, error) {
+	var builder strings.Builder
+	for chunk := range stream {
+		builder.WriteString(chunk)
+		if onChunk != nil {
+			if err := onChunk(chunk); err != nil {
+				return "", err
+			}
+		}
+	}
+	// The stream also ends early when ctx is cancelled
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// Server-Sent Events sent by SSEHandler.
+const (
+	EventChunk = "chunk" // data: {"content": "..."}
+	EventDone  = "done"  // data: {"conversationId": "..."}
+	EventError = "error" // data: {"code": "...", "error": "..."}
+)
+
+// SSEHandler is JSONHandler for streamed responses: logic sends each chunk of the
+// answer as a Server-Sent Event and returns the conversation ID for the final
+// "done" event. A client that disconnects cancels the request context, which
+// stops generation.
+func SSEHandler[T any](logic func(context.Context, T, ChunkFunc) (string, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, methodNotAllowed(r.Method))
+			return
+		}
+
+		// An empty body is an empty request, as in apiHandler; logic validates it
+		var req T
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, invalidRequest("Invalid request format"))
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, newAPIError(http.StatusInternalServerError, CodeInternal, "Streaming not supported"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		ctx := r.Context()
+		send := func(event string, data any) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			payload, err := json.Marshal(data)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+				return err
+			}
+			flusher.Flush()
+			return nil
+		}
+
+		conversationID, err := logic(ctx, req, func(chunk string) error {
+			return send(EventChunk, map[string]string{"content": chunk})
+		})
+		if ctx.Err() != nil {
+			log.Printf("SSE %s: client disconnected, generation stopped", r.URL.Path)
+			return
+		}
+		if err != nil {
+			log.Printf("SSE %s: %v", r.URL.Path, err)
+			_ = send(EventError, toAPIError(err))
+			return
+		}
+		done := map[string]string{}
+		if conversationID != "" {
+			done["conversationId"] = conversationID
+		}
+		_ = send(EventDone, done)
+	}
+}
@@ -0,0 +1,210 @@
+// internal/llm/structured_draft.go
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/prdraft"
+	"github.com/soyuz43/prbuddy-go/internal/prompts"
+	"github.com/soyuz43/prbuddy-go/internal/prtemplate"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// structuredDraftAttempts bounds how often an invalid JSON draft is sent back for correction.
+const structuredDraftAttempts = 3
+
+// StructuredDraft is a PR draft as returned by the model in JSON form.
+type StructuredDraft struct {
+	Title    string            `json:"title"`
+	Summary  string            `json:"summary"`
+	Changes  []string          `json:"changes"`
+	Testing  string            `json:"testing"`
+	Risks    string            `json:"risks"`
+	Breaking []string          `json:"breaking"`
+	Labels   []string          `json:"labels"`
+	Sections map[string]string `json:"sections,omitempty"` // PR template heading -> content
+
+	// The PR template's headings in order, so Markdown can follow its layout
+	TemplateHeadings []string `json:"template_headings,omitempty"`
+}
+
+// DraftSchema returns the JSON schema for a StructuredDraft. When the repository has a
+// PR template its headings become required keys of "sections".
+func DraftSchema(templateHeadings []string) map[string]interface{} {
+	stringArray := map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}
+	properties := map[string]interface{}{
+		"title":    map[string]interface{}{"type": "string"},
+		"summary":  map[string]interface{}{"type": "string"},
+		"changes":  stringArray,
+		"testing":  map[string]interface{}{"type": "string"},
+		"risks":    map[string]interface{}{"type": "string"},
+		"breaking": stringArray,
+		"labels":   stringArray,
+	}
+	required := []string{"title", "summary", "changes", "testing", "risks", "breaking", "labels"}
+
+	if len(templateHeadings) > 0 {
+		sectionProps := make(map[string]interface{}, len(templateHeadings))
+		for _, h := range templateHeadings {
+			sectionProps[h] = map[string]interface{}{"type": "string"}
+		}
+		properties["sections"] = map[string]interface{}{
+			"type":       "object",
+			"properties": sectionProps,
+			"required":   templateHeadings,
+		}
+		required = append(required, "sections")
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// ParseStructuredDraft decodes and validates a model response. Code fences and text
+// around the outermost JSON object are tolerated.
+func ParseStructuredDraft(raw string, templateHeadings []string) (*StructuredDraft, error) {
+	text := utils.StripOuterMarkdownCodeFence(strings.TrimSpace(raw))
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("response contains no JSON object")
+	}
+
+	var draft StructuredDraft
+	if err := json.Unmarshal([]byte(text[start:end+1]), &draft); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	draft.TemplateHeadings = templateHeadings
+	if err := draft.Validate(); err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// Validate checks the fields a usable PR needs.
+func (d *StructuredDraft) Validate() error {
+	d.Title = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(d.Title), "# "))
+	switch {
+	case d.Title == "":
+		return fmt.Errorf(`"title" is empty`)
+	case strings.Contains(d.Title, "\n"):
+		return fmt.Errorf(`"title" must be a single line`)
+	case utf8.RuneCountInString(d.Title) > prdraft.MaxTitleLength:
+		return fmt.Errorf(`"title" is longer than %d characters`, prdraft.MaxTitleLength)
+	case strings.TrimSpace(d.Summary) == "":
+		return fmt.Errorf(`"summary" is empty`)
+	case len(d.Changes) == 0:
+		return fmt.Errorf(`"changes" must list at least one change`)
+	}
+
+	var missing []string
+	for _, h := range d.TemplateHeadings {
+		if strings.TrimSpace(d.section(h)) == "" {
+			missing = append(missing, h)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf(`"sections" is missing %s`, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// section looks up a template section case-insensitively; models often normalise keys.
+func (d *StructuredDraft) section(heading string) string {
+	if v, ok := d.Sections[heading]; ok {
+		return v
+	}
+	for k, v := range d.Sections {
+		if strings.EqualFold(strings.TrimSpace(k), heading) {
+			return v
+		}
+	}
+	return ""
+}
+
+// Markdown renders the PR body. With a PR template the body follows its headings;
+// otherwise a fixed Summary/Changes/Testing/Risks layout is used.
+func (d *StructuredDraft) Markdown() string {
+	var b strings.Builder
+	b.WriteString("# " + d.Title + "\n")
+
+	if len(d.TemplateHeadings) > 0 {
+		for _, h := range d.TemplateHeadings {
+			b.WriteString(fmt.Sprintf("\n## %s\n\n%s\n", h, strings.TrimSpace(d.section(h))))
+		}
+	} else {
+		b.WriteString("\n## Summary\n\n" + strings.TrimSpace(d.Summary) + "\n")
+		b.WriteString("\n## Changes\n\n" + bulletList(d.Changes))
+		if t := strings.TrimSpace(d.Testing); t != "" {
+			b.WriteString("\n## Testing\n\n" + t + "\n")
+		}
+		if r := strings.TrimSpace(d.Risks); r != "" {
+			b.WriteString("\n## Risks\n\n" + r + "\n")
+		}
+	}
+
+	if len(d.Breaking) > 0 && len(prtemplate.MissingHeadings(b.String(), []string{"Breaking Changes"})) > 0 {
+		b.WriteString("\n## Breaking Changes\n\n" + bulletList(d.Breaking))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func bulletList(items []string) string {
+	var b strings.Builder
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			b.WriteString("- " + strings.TrimPrefix(item, "- ") + "\n")
+		}
+	}
+	return b.String()
+}
+
+// generateStructuredDraft asks for a JSON draft, sending invalid responses back with
+// the validation error until it parses or the attempts run out.
+func generateStructuredDraft(ctx context.Context, data prompts.Data) (*StructuredDraft, error) {
+	var headings []string
+	if tmpl := withPRTemplate(&data); tmpl != nil {
+		headings = tmpl.Headings
+	}
+
+	prompt, err := prompts.Render(prompts.DraftJSON, data)
+	if err != nil {
+		return nil, err
+	}
+	messages := []contextpkg.Message{
+		{Role: "system", Content: "You are a helpful assistant that replies with JSON only."},
+		{Role: "user", Content: prompt},
+	}
+	schema := DraftSchema(headings)
+
+	var lastErr error
+	for attempt := 1; attempt <= structuredDraftAttempts; attempt++ {
+		response, err := structuredResponse(ctx, llmClient, messages, schema)
+		if err != nil {
+			return nil, err
+		}
+
+		draft, err := ParseStructuredDraft(response, headings)
+		if err == nil {
+			return draft, nil
+		}
+		lastErr = err
+		logrus.Warnf("Structured draft attempt %d/%d invalid: %v", attempt, structuredDraftAttempts, err)
+
+		messages = append(messages,
+			contextpkg.Message{Role: "assistant", Content: response},
+			contextpkg.Message{Role: "user", Content: fmt.Sprintf(
+				"That response was not usable: %v. Reply again with only the corrected JSON object.", err)},
+		)
+	}
+	return nil, fmt.Errorf("no valid structured draft after %d attempts: %w", structuredDraftAttempts, lastErr)
+}
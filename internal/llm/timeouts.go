@@ -0,0 +1,58 @@
+// internal/llm/timeouts.go
+
+package llm
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Operation identifies a class of LLM work with its own deadline.
+type Operation string
+
+const (
+	OpDraft       Operation = "draft"
+	OpWhat        Operation = "what"
+	OpQuickAssist Operation = "quickassist"
+	OpCommitMsg   Operation = "commit_msg"
+)
+
+// Default deadlines per operation. Drafts see the largest prompts, so they get the most time.
+var defaultOperationTimeouts = map[Operation]time.Duration{
+	OpDraft:       5 * time.Minute,
+	OpWhat:        3 * time.Minute,
+	OpQuickAssist: 2 * time.Minute,
+	OpCommitMsg:   45 * time.Second, // runs inside git commit, so it must give up quickly
+}
+
+// operationTimeoutEnv maps each operation to its override variable (Go duration syntax, e.g. "90s").
+var operationTimeoutEnv = map[Operation]string{
+	OpDraft:       "PRBUDDY_DRAFT_TIMEOUT",
+	OpWhat:        "PRBUDDY_WHAT_TIMEOUT",
+	OpQuickAssist: "PRBUDDY_QUICKASSIST_TIMEOUT",
+	OpCommitMsg:   "PRBUDDY_COMMIT_MSG_TIMEOUT",
+}
+
+// discoveryClient bounds model listing calls, which happen on every config lookup without an active model.
+var discoveryClient = &http.Client{Timeout: 10 * time.Second}
+
+// OperationTimeout returns the configured deadline for op, falling back to its default.
+func OperationTimeout(op Operation) time.Duration {
+	if raw := os.Getenv(operationTimeoutEnv[op]); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err == nil && d > 0 {
+			return d
+		}
+		logrus.Warnf("Ignoring invalid %s=%q; using default", operationTimeoutEnv[op], raw)
+	}
+	return defaultOperationTimeouts[op]
+}
+
+// WithOperationTimeout derives a context bounded by the deadline configured for op.
+func WithOperationTimeout(parent context.Context, op Operation) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(parent, OperationTimeout(op))
+}
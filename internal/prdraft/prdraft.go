@@ -0,0 +1,234 @@
+// internal/prdraft/prdraft.go
+
+package prdraft
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/soyuz43/prbuddy-go/internal/prtemplate"
+)
+
+// GitHub rejects pull requests whose title or body exceed these lengths, counted
+// in characters (runes). Generated and edited drafts are held to the same limits.
+const (
+	MaxTitleLength = 256
+	MaxBodyLength  = 65536
+)
+
+// Title returns the text of the draft's first H1 or H2 heading, outside code
+// fences; empty when there is none.
+func Title(draft string) string {
+	inFence := false
+	for _, line := range strings.Split(draft, "\n") {
+		line = strings.TrimSpace(line)
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if strings.HasPrefix(line, "# ") || strings.HasPrefix(line, "## ") {
+			title := strings.TrimPrefix(line, "# ")
+			title = strings.TrimPrefix(title, "## ")
+			return strings.TrimSpace(title)
+		}
+	}
+	return ""
+}
+
+// Validate returns what keeps draft from being submitted as a pull request:
+// a missing or overlong title, an overlong body, headings of the PR template
+// that are missing, and code fences left over from the model's answer.
+func Validate(draft string, requiredHeadings []string) []string {
+	var problems []string
+
+	title := Title(draft)
+	switch {
+	case !startsWithTitle(draft):
+		problems = append(problems, "no title: start the draft with a '# Title' heading")
+	case utf8.RuneCountInString(title) > MaxTitleLength:
+		problems = append(problems, fmt.Sprintf("title is %d characters long, GitHub allows %d", utf8.RuneCountInString(title), MaxTitleLength))
+	}
+	if n := len([]rune(draft)); n > MaxBodyLength {
+		problems = append(problems, fmt.Sprintf("description is %d characters long, GitHub allows %d", n, MaxBodyLength))
+	}
+
+	for _, h := range prtemplate.MissingHeadings(draft, requiredHeadings) {
+		problems = append(problems, fmt.Sprintf("missing PR template section %q", h))
+	}
+
+	var fences []string
+	for _, line := range strings.Split(draft, "\n") {
+		if line = strings.TrimSpace(line); isFence(line) {
+			fences = append(fences, line)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(draft), "\n")
+	switch {
+	case len(fences)%2 == 1:
+		problems = append(problems, "unclosed code fence")
+	case len(fences) > 0 && isFence(strings.TrimSpace(lines[0])) && isFence(strings.TrimSpace(lines[len(lines)-1])):
+		problems = append(problems, "the whole draft is wrapped in a code fence; remove the outer ``` lines")
+	}
+	return problems
+}
+
+// startsWithTitle reports whether the first line outside code fences is an H1. Title
+// falls back to an H2 for generated drafts, but an edited draft whose '# Title' line
+// was deleted must not be titled after its first section.
+func startsWithTitle(draft string) bool {
+	inFence := false
+	for _, line := range strings.Split(draft, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case isFence(line):
+			inFence = !inFence
+		case inFence, line == "":
+		default:
+			return strings.HasPrefix(line, "# ")
+		}
+	}
+	return false
+}
+
+func isFence(line string) bool {
+	return strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~")
+}
+
+// instructionsRe matches the comment WithInstructions adds, which GitHub would
+// otherwise keep (hidden) in the PR body.
+var instructionsRe = regexp.MustCompile(`(?s)\n*<!-- prbuddy-go:.*?-->\n*`)
+
+// WithInstructions appends editing instructions, and the problems found by an
+// earlier Validate, to draft as an HTML comment that StripInstructions removes.
+func WithInstructions(draft string, problems []string) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(draft, "\n"))
+	b.WriteString("\n\n<!-- prbuddy-go: Edit the pull request above. The first line, a '# ' heading, is\n")
+	b.WriteString("the title, everything else is the description. This comment is removed.\n")
+	b.WriteString("An empty draft aborts the pull request.\n")
+	if len(problems) > 0 {
+		b.WriteString("\nPlease fix:\n")
+		for _, p := range problems {
+			b.WriteString("  - " + p + "\n")
+		}
+	}
+	b.WriteString("-->\n")
+	return b.String()
+}
+
+// StripInstructions removes the comment added by WithInstructions.
+func StripInstructions(content string) string {
+	return strings.TrimSpace(instructionsRe.ReplaceAllString(content, "\n"))
+}
+
+// Markers around a part of a PR body that 'pr update' carries over unchanged.
+const (
+	KeepStart = "<!-- prbuddy:keep -->"
+	KeepEnd   = "<!-- /prbuddy:keep -->"
+)
+
+// ChangesHeading starts the section 'pr update' lists new commits in.
+const ChangesHeading = "## Changes since last update"
+
+var keepRe = regexp.MustCompile(`(?s)` + regexp.QuoteMeta(KeepStart) + `.*?` + regexp.QuoteMeta(KeepEnd))
+
+// KeptSections returns the marked sections of body, markers included. A start
+// marker without an end keeps the rest of the body.
+func KeptSections(body string) []string {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	sections := keepRe.FindAllString(body, -1)
+	rest := keepRe.ReplaceAllString(body, "")
+	if i := strings.Index(rest, KeepStart); i >= 0 {
+		sections = append(sections, strings.TrimSpace(rest[i:])+"\n"+KeepEnd)
+	}
+	return sections
+}
+
+// UpdatedBody is the new body of a PR: draft, the sections of the previous body
+// marked to keep, and a list of the changes since the last update.
+func UpdatedBody(draft, previous string, changes []string) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(keepRe.ReplaceAllString(draft, "")))
+	for _, section := range KeptSections(previous) {
+		b.WriteString("\n\n" + section)
+	}
+	if len(changes) > 0 {
+		b.WriteString("\n\n" + ChangesHeading + "\n\n")
+		for _, c := range changes {
+			b.WriteString("- " + c + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// Markers around the "Stack" section listing the PRs of a stack.
+const (
+	StackStart = "<!-- prbuddy:stack -->"
+	StackEnd   = "<!-- /prbuddy:stack -->"
+)
+
+var stackRe = regexp.MustCompile(`(?s)` + regexp.QuoteMeta(StackStart) + `.*?` + regexp.QuoteMeta(StackEnd))
+
+// StackEntry is a PR of a stack; Current marks the PR whose body is written.
+type StackEntry struct {
+	Title   string
+	URL     string
+	Current bool
+}
+
+// StackSection renders the "Stack" section for entries, bottom of the stack
+// first. A PR on its own is no stack: the section is empty.
+func StackSection(entries []StackEntry) string {
+	if len(entries) < 2 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(StackStart + "\n## Stack\n\n")
+	for i, e := range entries {
+		if e.Current {
+			fmt.Fprintf(&b, "%d. **%s** (this PR)\n", i+1, e.Title)
+		} else {
+			fmt.Fprintf(&b, "%d. [%s](%s)\n", i+1, e.Title, e.URL)
+		}
+	}
+	b.WriteString(StackEnd)
+	return b.String()
+}
+
+// Stack returns the "Stack" section of body, or "".
+func Stack(body string) string {
+	return stackRe.FindString(strings.ReplaceAll(body, "\r\n", "\n"))
+}
+
+// WithStack replaces the "Stack" section of body with section, appending it when
+// body has none and removing it when section is empty. body is returned unchanged
+// when its section is already up to date.
+func WithStack(body, section string) string {
+	normalized := strings.ReplaceAll(body, "\r\n", "\n")
+	loc := stackRe.FindStringIndex(normalized)
+	switch {
+	case loc == nil && section == "":
+		return body
+	case loc == nil:
+		return strings.TrimRight(normalized, "\n") + "\n\n" + section + "\n"
+	case normalized[loc[0]:loc[1]] == section:
+		return body
+	}
+
+	var parts []string
+	for _, part := range []string{
+		strings.TrimRight(normalized[:loc[0]], "\n"),
+		section,
+		strings.TrimLeft(normalized[loc[1]:], "\n"),
+	} {
+		if strings.TrimSpace(part) != "" {
+			parts = append(parts, strings.TrimRight(part, "\n"))
+		}
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
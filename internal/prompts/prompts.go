@@ -0,0 +1,172 @@
+// internal/prompts/prompts.go
+
+package prompts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+//go:embed defaults/*.tmpl
+var defaults embed.FS
+
+// Template names.
+const (
+	Draft       = "draft"       // PR description from a commit message and diff (or file summaries)
+	DraftJSON   = "draft_json"  // the same, as a JSON object for structured drafts
+	Summary     = "summary"     // per-file summary used when drafting very large diffs
+	What        = "what"        // summary of uncommitted work
+	QuickAssist = "quickassist" // system prompt for quickassist conversations
+	CommitMsg   = "commit_msg"  // commit message for the staged changes
+)
+
+// OverrideDir is where repo-local templates live, relative to the repository root.
+const OverrideDir = ".prbuddy/prompts"
+
+// Data holds every variable available to a template. Not all fields are set for
+// every template: e.g. Tasks is only filled for DCE-assisted summaries.
+type Data struct {
+	CommitMessage string
+	Diff          string
+	DiffHeading   string // "Code Changes", or "Summarised Code Changes" for map-reduce drafts
+	Branch        string
+	Base          string
+	Files         []string
+	Tasks         []contextpkg.Task
+
+	// The repository's pull request template, when one exists (draft only)
+	PRTemplate       string
+	RequiredHeadings []string
+
+	// Recent commit subjects and whether they follow Conventional Commits (commit_msg only)
+	RecentCommits []string
+	Conventional  bool
+}
+
+var funcs = template.FuncMap{
+	"join":  strings.Join,
+	"trim":  strings.TrimSpace,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// Names lists the known templates.
+func Names() []string {
+	entries, _ := defaults.ReadDir("defaults")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".tmpl"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default returns the embedded source of a template.
+func Default(name string) (string, error) {
+	data, err := defaults.ReadFile("defaults/" + name + ".tmpl")
+	if err != nil {
+		return "", fmt.Errorf("unknown prompt template %q (known: %s)", name, strings.Join(Names(), ", "))
+	}
+	return string(data), nil
+}
+
+// OverridePath returns the repo-local path a template override would be read from.
+func OverridePath(name string) (string, error) {
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return "", fmt.Errorf("repo path detection: %w", err)
+	}
+	return filepath.Join(repoPath, OverrideDir, name+".tmpl"), nil
+}
+
+// Source returns the template source in effect for name and where it came from:
+// the override file path, or "embedded".
+func Source(name string) (string, string, error) {
+	def, err := Default(name)
+	if err != nil {
+		return "", "", err
+	}
+	if path, err := OverridePath(name); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			return string(data), path, nil
+		}
+	}
+	return def, "embedded", nil
+}
+
+// Hash identifies the source of the template in effect for name (the first 12 hex
+// digits of its SHA-256), so saved drafts record which version of a prompt made them.
+func Hash(name string) string {
+	src, _, err := Source(name)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(src))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Render executes the template name with data. A broken override is reported and
+// the embedded default used instead, so a typo never stops a commit hook.
+func Render(name string, data Data) (string, error) {
+	src, origin, err := Source(name)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := execute(name, src, data)
+	if err == nil || origin == "embedded" {
+		return out, err
+	}
+
+	logrus.Warnf("Prompt template %s is invalid, using the built-in default: %v", origin, err)
+	def, _ := Default(name)
+	return execute(name, def, data)
+}
+
+func execute(name, src string, data Data) (string, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return "", fmt.Errorf("parse %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render %s template: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// Init writes the embedded defaults to .prbuddy/prompts for customisation. Existing
+// files are left alone unless overwrite is set. It returns the files written.
+func Init(overwrite bool) ([]string, error) {
+	var written []string
+	for _, name := range Names() {
+		path, err := OverridePath(name)
+		if err != nil {
+			return written, err
+		}
+		if utils.FileExists(path) && !overwrite {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return written, fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+		}
+		def, _ := Default(name)
+		if err := os.WriteFile(path, []byte(def), 0644); err != nil {
+			return written, fmt.Errorf("write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
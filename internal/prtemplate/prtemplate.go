@@ -0,0 +1,173 @@
+// internal/prtemplate/prtemplate.go
+
+package prtemplate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// Template is a repository pull request template and the headings it requires.
+type Template struct {
+	Path     string
+	Content  string
+	Headings []string
+}
+
+// searchDirs are the places GitHub looks for PR templates, relative to the repo root.
+var searchDirs = []string{".github", "", "docs"}
+
+const (
+	singleTemplateName = "pull_request_template.md"
+	multiTemplateDir   = "pull_request_template"
+)
+
+// Find discovers the repository's PR template. GitHub matches these names case-insensitively
+// in .github/, the root and docs/; a PULL_REQUEST_TEMPLATE/ directory may hold several,
+// in which case PRBUDDY_PR_TEMPLATE picks one by file name, otherwise default.md or the
+// first alphabetically. It returns nil without error when the repo has no template.
+func Find() (*Template, error) {
+	repoPath, err := utils.GetRepoPath()
+	if err != nil {
+		return nil, fmt.Errorf("repo path detection: %w", err)
+	}
+
+	if explicit := os.Getenv("PRBUDDY_PR_TEMPLATE"); filepath.IsAbs(explicit) || strings.Contains(explicit, "/") {
+		path := explicit
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(repoPath, path)
+		}
+		return Load(path)
+	}
+
+	for _, dir := range searchDirs {
+		entries, err := os.ReadDir(filepath.Join(repoPath, dir))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := strings.ToLower(e.Name())
+			switch {
+			case !e.IsDir() && name == singleTemplateName:
+				return Load(filepath.Join(repoPath, dir, e.Name()))
+			case e.IsDir() && name == multiTemplateDir:
+				if path := pickFromMultiDir(filepath.Join(repoPath, dir, e.Name())); path != "" {
+					return Load(path)
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// pickFromMultiDir chooses one template from a PULL_REQUEST_TEMPLATE/ directory.
+func pickFromMultiDir(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".md") {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+
+	want := os.Getenv("PRBUDDY_PR_TEMPLATE")
+	for _, preferred := range []string{want, want + ".md", "default.md"} {
+		for _, n := range names {
+			if preferred != "" && strings.EqualFold(n, preferred) {
+				return filepath.Join(dir, n)
+			}
+		}
+	}
+	return filepath.Join(dir, names[0])
+}
+
+// Load reads the template at path.
+func Load(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read PR template: %w", err)
+	}
+	content := string(data)
+	return &Template{Path: path, Content: content, Headings: ParseHeadings(content)}, nil
+}
+
+var (
+	headingRe     = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+	htmlCommentRe = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// ParseHeadings returns the text of every markdown heading in content, skipping
+// fenced code blocks and HTML comments (templates often hold instructions there).
+func ParseHeadings(content string) []string {
+	content = htmlCommentRe.ReplaceAllString(content, "")
+
+	var headings []string
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if m := headingRe.FindStringSubmatch(trimmed); m != nil {
+			headings = append(headings, m[2])
+		}
+	}
+	return headings
+}
+
+// normalizeHeading makes "## Testing:" and "### testing" compare equal.
+func normalizeHeading(h string) string {
+	h = strings.ToLower(strings.TrimSpace(h))
+	h = strings.Trim(h, ":*_` ")
+	return strings.Join(strings.Fields(h), " ")
+}
+
+// MissingHeadings returns the required headings that draft does not contain, at any level.
+func MissingHeadings(draft string, required []string) []string {
+	present := make(map[string]bool)
+	for _, h := range ParseHeadings(draft) {
+		present[normalizeHeading(h)] = true
+	}
+	var missing []string
+	for _, h := range required {
+		if !present[normalizeHeading(h)] {
+			missing = append(missing, h)
+		}
+	}
+	return missing
+}
+
+// Placeholder is written under headings the model failed to fill in.
+const Placeholder = "_TODO: not provided by the draft generator; please fill in._"
+
+// FillMissing appends a placeholder section for every missing heading so the
+// result always satisfies the template's structure.
+func FillMissing(draft string, missing []string) string {
+	if len(missing) == 0 {
+		return draft
+	}
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(draft, "\n"))
+	for _, h := range missing {
+		b.WriteString(fmt.Sprintf("\n\n## %s\n\n%s", h, Placeholder))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
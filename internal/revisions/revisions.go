@@ -0,0 +1,105 @@
+// internal/revisions/revisions.go
+
+package revisions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+// Dir is the subdirectory of an artifact directory that holds its numbered revisions.
+const Dir = "revisions"
+
+// Revision is one numbered snapshot of an artifact directory's files.
+type Revision struct {
+	Number int
+	Path   string
+}
+
+// Path returns where revision n of artifactDir is stored, e.g. revisions/003.
+func Path(artifactDir string, n int) string {
+	return filepath.Join(artifactDir, Dir, fmt.Sprintf("%03d", n))
+}
+
+// List returns the revisions of artifactDir, oldest first. A directory without
+// revisions yields none and no error.
+func List(artifactDir string) ([]Revision, error) {
+	entries, err := os.ReadDir(filepath.Join(artifactDir, Dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list revisions: %w", err)
+	}
+
+	var revs []Revision
+	for _, e := range entries {
+		n, err := strconv.Atoi(e.Name())
+		if err != nil || !e.IsDir() || n <= 0 {
+			continue
+		}
+		revs = append(revs, Revision{Number: n, Path: filepath.Join(artifactDir, Dir, e.Name())})
+	}
+	sort.Slice(revs, func(i, j int) bool { return revs[i].Number < revs[j].Number })
+	return revs, nil
+}
+
+// Next returns the number the next revision of artifactDir will get.
+func Next(artifactDir string) (int, error) {
+	revs, err := List(artifactDir)
+	if err != nil {
+		return 0, err
+	}
+	if len(revs) == 0 {
+		return 1, nil
+	}
+	return revs[len(revs)-1].Number + 1, nil
+}
+
+// Save snapshots the named files of artifactDir as revision n. Files that don't
+// exist are skipped, so optional artifacts need no special casing.
+func Save(artifactDir string, n int, files []string) error {
+	dest := Path(artifactDir, n)
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("revision %d already exists", n)
+	}
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return fmt.Errorf("create revision %d: %w", n, err)
+	}
+	return copyFiles(artifactDir, dest, files)
+}
+
+// Restore makes revision n current again by copying its files back over the named
+// files of artifactDir. Files the revision doesn't have are removed.
+func Restore(artifactDir string, n int, files []string) error {
+	src := Path(artifactDir, n)
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("revision %d does not exist", n)
+	}
+	return copyFiles(src, artifactDir, files)
+}
+
+// copyFiles mirrors the named files from src into dest.
+func copyFiles(src, dest string, files []string) error {
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join(src, name))
+		if os.IsNotExist(err) {
+			if err := os.Remove(filepath.Join(dest, name)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := utils.WriteFile(filepath.Join(dest, name), data); err != nil {
+			return fmt.Errorf("copy %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ type DiffMode int
 const (
 	DiffSinceLastCommit DiffMode = iota
 	DiffAllLocalChanges
+	DiffStaged
 )
 
 // GetDiffs returns diffs based on the given mode.
@@ -45,6 +46,10 @@ func GetDiffs(mode DiffMode) (string, error) {
 
 		return builder.String(), nil
 
+	case DiffStaged:
+		// No HEAD needed, so this also works for the first commit of a repository
+		return ExecGit("diff", "--cached")
+
 	default:
 		return "", fmt.Errorf("unknown diff mode: %d", mode)
 	}
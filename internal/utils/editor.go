@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Editor returns the command used to edit files: $VISUAL, then $EDITOR, then
+// whatever git would use (core.editor, falling back to vi).
+func Editor() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if e := strings.TrimSpace(os.Getenv(env)); e != "" {
+			return e
+		}
+	}
+	if e, err := ExecGit("var", "GIT_EDITOR"); err == nil && e != "" {
+		return e
+	}
+	return "vi"
+}
+
+// EditFile opens path in the user's editor and waits for it to exit. The editor
+// runs through the shell so values like "code --wait" work, as they do for git.
+func EditFile(path string) error {
+	editor := Editor()
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
+}
@@ -95,3 +95,23 @@ func GetCurrentBranch() (string, error) {
 func GetLatestCommit() (string, error) {
 	return ExecGit("rev-parse", "HEAD")
 }
+
+// GetMergeBase returns the merge base of HEAD and base, preferring the remote-tracking
+// branch origin/<base> (local base branches are often stale) and falling back to <base>.
+func GetMergeBase(base string) (string, error) {
+	var lastErr error
+	for _, ref := range []string{"origin/" + base, base} {
+		if _, err := ExecGit("rev-parse", "--verify", "--quiet", ref); err != nil {
+			continue
+		}
+		mergeBase, err := ExecGit("merge-base", ref, "HEAD")
+		if err == nil && mergeBase != "" {
+			return strings.TrimSpace(mergeBase), nil
+		}
+		lastErr = err
+	}
+	if lastErr != nil {
+		return "", fmt.Errorf("merge-base with %s: %w", base, lastErr)
+	}
+	return "", fmt.Errorf("base branch %q not found locally or on origin", base)
+}
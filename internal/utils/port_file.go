@@ -13,8 +13,10 @@ import (
 const (
 	appName      = "prbuddy-go"
 	portFileName = "port"
-	filePerm     = 0600 // rw-------
-	dirPerm      = 0700 // rwx------
+	// tokenFileName holds the bearer token of the running server, next to its port
+	tokenFileName = "token"
+	filePerm      = 0600 // rw-------
+	dirPerm       = 0700 // rwx------
 )
 
 // EnsureAppCacheDir creates (if necessary) and verifies the application cache directory.
@@ -62,6 +64,22 @@ func WritePortFile(port int) error {
 		return fmt.Errorf("invalid port number: %d", port)
 	}
 
+	return writeCacheFile(portFileName, strconv.Itoa(port))
+}
+
+// WriteTokenFile writes the server's bearer token atomically next to the port file,
+// readable only by the current user.
+func WriteTokenFile(token string) error {
+	if token == "" {
+		return fmt.Errorf("empty token")
+	}
+
+	return writeCacheFile(tokenFileName, token)
+}
+
+// writeCacheFile replaces the cache file name with content: written to a locked
+// temp file (created 0600), synced, then renamed into place.
+func writeCacheFile(name, content string) error {
 	if err := EnsureAppCacheDir(); err != nil {
 		return fmt.Errorf("cache directory validation failed: %w", err)
 	}
@@ -71,26 +89,26 @@ func WritePortFile(port int) error {
 		return err
 	}
 
-	tmpFile, err := os.CreateTemp(cacheDir, "port-*.tmp")
+	tmpFile, err := os.CreateTemp(cacheDir, name+"-*.tmp")
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %w", err)
 	}
 	defer cleanupTempFile(tmpFile)
 
-	if err := performAtomicWrite(tmpFile, port); err != nil {
+	if err := performAtomicWrite(tmpFile, name, content); err != nil {
 		return err
 	}
 
-	return finalizePortFile(tmpFile, cacheDir)
+	return finalizeCacheFile(tmpFile, filepath.Join(cacheDir, name))
 }
 
-func performAtomicWrite(tmpFile *os.File, port int) error {
+func performAtomicWrite(tmpFile *os.File, name, content string) error {
 	if err := syscall.Flock(int(tmpFile.Fd()), syscall.LOCK_EX); err != nil {
 		return fmt.Errorf("file lock failed: %w", err)
 	}
 
-	if _, err := fmt.Fprintf(tmpFile, "%d", port); err != nil {
-		return fmt.Errorf("port write failed: %w", err)
+	if _, err := tmpFile.WriteString(content); err != nil {
+		return fmt.Errorf("%s write failed: %w", name, err)
 	}
 
 	if err := tmpFile.Sync(); err != nil {
@@ -100,13 +118,15 @@ func performAtomicWrite(tmpFile *os.File, port int) error {
 	return nil
 }
 
-func finalizePortFile(tmpFile *os.File, cacheDir string) error {
-	finalPath := filepath.Join(cacheDir, portFileName)
+func finalizeCacheFile(tmpFile *os.File, finalPath string) error {
+	if err := os.Chmod(tmpFile.Name(), filePerm); err != nil {
+		return fmt.Errorf("chmod failed: %w", err)
+	}
 	if err := os.Rename(tmpFile.Name(), finalPath); err != nil {
 		return fmt.Errorf("atomic rename failed: %w", err)
 	}
 
-	return os.Chmod(finalPath, filePerm)
+	return nil
 }
 
 func cleanupTempFile(tmpFile *os.File) {
@@ -116,31 +136,54 @@ func cleanupTempFile(tmpFile *os.File) {
 
 // ReadPortFile reads and validates the port number from the port file.
 func ReadPortFile() (int, error) {
+	data, err := readCacheFile(portFileName)
+	if err != nil {
+		return 0, err
+	}
+
+	return validatePortData(data)
+}
+
+// ReadTokenFile reads the bearer token of the running server.
+func ReadTokenFile() (string, error) {
+	data, err := readCacheFile(tokenFileName)
+	if err != nil {
+		return "", err
+	}
+
+	token := string(bytes.TrimSpace(data))
+	if token == "" {
+		return "", fmt.Errorf("empty token file")
+	}
+	return token, nil
+}
+
+func readCacheFile(name string) ([]byte, error) {
 	if err := EnsureAppCacheDir(); err != nil {
-		return 0, fmt.Errorf("cache directory validation failed: %w", err)
+		return nil, fmt.Errorf("cache directory validation failed: %w", err)
 	}
 
 	cacheDir, err := getAppCacheDirPath()
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	file, err := os.Open(filepath.Join(cacheDir, portFileName))
+	file, err := os.Open(filepath.Join(cacheDir, name))
 	if err != nil {
-		return 0, fmt.Errorf("failed to open port file: %w", err)
+		return nil, fmt.Errorf("failed to open %s file: %w", name, err)
 	}
 	defer file.Close()
 
 	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_SH); err != nil {
-		return 0, fmt.Errorf("file lock failed: %w", err)
+		return nil, fmt.Errorf("file lock failed: %w", err)
 	}
 
 	data, err := io.ReadAll(file)
 	if err != nil {
-		return 0, fmt.Errorf("read failed: %w", err)
+		return nil, fmt.Errorf("read failed: %w", err)
 	}
 
-	return validatePortData(data)
+	return data, nil
 }
 
 func validatePortData(data []byte) (int, error) {
@@ -163,13 +206,22 @@ func validatePortData(data []byte) (int, error) {
 
 // DeletePortFile removes the port file.
 func DeletePortFile() error {
+	return deleteCacheFile(portFileName)
+}
+
+// DeleteTokenFile removes the token file.
+func DeleteTokenFile() error {
+	return deleteCacheFile(tokenFileName)
+}
+
+func deleteCacheFile(name string) error {
 	cacheDir, err := getAppCacheDirPath()
 	if err != nil {
 		return err
 	}
 
-	portPath := filepath.Join(cacheDir, portFileName)
-	if err := os.Remove(portPath); err != nil {
+	path := filepath.Join(cacheDir, name)
+	if err := os.Remove(path); err != nil {
 		if os.IsNotExist(err) {
 			return nil
 		}
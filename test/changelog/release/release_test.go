@@ -0,0 +1,86 @@
+// test/changelog/release/release_test.go
+package release_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/changelog"
+)
+
+func entries() []changelog.Entry {
+	feat := changelog.NewEntry("aaaaaaa1111", "feat(dce): add task refresh\n\nBREAKING CHANGE: tasks reload every turn")
+	feat.SetDraft("feat(dce): Refresh DCE tasks on every turn", "Tasks are rebuilt from the conversation.")
+	return []changelog.Entry{
+		changelog.NewEntry("bbbbbbb2222", "fix: handle empty diff"),
+		feat,
+		changelog.NewEntry("ccccccc3333", "chore: bump deps"),
+		changelog.NewEntry("ddddddd4444", "Update README"),
+	}
+}
+
+func TestBuild_GroupsByTypeAndHidesChores(t *testing.T) {
+	release := changelog.Release{Version: "1.2.0", Date: "2026-10-01", To: "HEAD"}
+	release.Build(entries(), false)
+
+	var types []string
+	for _, g := range release.Groups {
+		types = append(types, g.Type)
+	}
+	if got := strings.Join(types, ","); got != "feat,fix,other" {
+		t.Errorf("Expected groups feat,fix,other, got %s", got)
+	}
+
+	release.Build(entries(), true)
+	if len(release.Groups) != 4 {
+		t.Errorf("Expected chore group with all set, got %d groups", len(release.Groups))
+	}
+}
+
+func TestMarkdown_KeepAChangelogSections(t *testing.T) {
+	release := changelog.Release{Version: "1.2.0", Date: "2026-10-01", To: "HEAD"}
+	release.Build(entries(), false)
+
+	want := `## [1.2.0] - 2026-10-01
+
+### Added
+
+- **BREAKING:** **dce:** Refresh DCE tasks on every turn (aaaaaaa)
+  - tasks reload every turn
+
+### Changed
+
+- Update README (ddddddd)
+
+### Fixed
+
+- handle empty diff (bbbbbbb)`
+	if got := release.Markdown(); got != want {
+		t.Errorf("Unexpected markdown:\n%s", got)
+	}
+}
+
+func TestPrepend_ReplacesSameRelease(t *testing.T) {
+	first := changelog.Prepend("", "## [Unreleased]\n\n### Fixed\n\n- one")
+	if !strings.HasPrefix(first, "# Changelog\n") || !strings.HasSuffix(first, "- one\n") {
+		t.Fatalf("Expected a new changelog, got:\n%s", first)
+	}
+
+	withRelease := strings.Replace(first, "## [Unreleased]", "## [1.0.0] - 2026-01-01", 1)
+	second := changelog.Prepend(withRelease, "## [Unreleased]\n\n### Fixed\n\n- two")
+	third := changelog.Prepend(second, "## [Unreleased]\n\n### Fixed\n\n- three")
+
+	if strings.Count(third, "## [Unreleased]") != 1 || strings.Contains(third, "- two") {
+		t.Errorf("Expected the Unreleased section to be replaced, got:\n%s", third)
+	}
+	if strings.Index(third, "- three") > strings.Index(third, "## [1.0.0]") {
+		t.Errorf("Expected the new release above older ones, got:\n%s", third)
+	}
+}
+
+func TestDraftText(t *testing.T) {
+	title, summary := changelog.DraftText("# Add cache\n\n> [!WARNING]\n\n## Summary\n\nCaches widgets\nin memory.\n\n## Changes\n\n- one")
+	if title != "Add cache" || summary != "Caches widgets in memory." {
+		t.Errorf("Unexpected title %q / summary %q", title, summary)
+	}
+}
@@ -0,0 +1,178 @@
+// test/contextpkg/budget/budget_test.go
+package budget_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+)
+
+func numberedLines(prefix string, n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%s line %03d with some padding text", prefix, i)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// conversation is a DCE conversation: system prompt, DCE log lines, chat history,
+// the DCE snapshot and the final message carrying the diff.
+func conversation() []contextpkg.Message {
+	messages := []contextpkg.Message{{Role: "system", Content: "You are a helpful assistant."}}
+	for i := 0; i < 3; i++ {
+		messages = append(messages, contextpkg.Message{Role: "system", Content: "[DCE] " + strings.Repeat("log ", 50)})
+	}
+	for i := 0; i < 4; i++ {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		messages = append(messages, contextpkg.Message{Role: role, Content: fmt.Sprintf("history %d ", i) + strings.Repeat("chat ", 80)})
+	}
+	messages = append(messages,
+		contextpkg.Message{Role: "system", Content: "ACTIVE DEVELOPMENT CONTEXT\n" + numberedLines("snapshot", 100)},
+		contextpkg.Message{Role: "user", Content: numberedLines("diff", 200)},
+	)
+	return messages
+}
+
+func tokens(messages ...contextpkg.Message) int {
+	return contextpkg.EstimateContextTokens(messages)
+}
+
+func contents(messages []contextpkg.Message) string {
+	var parts []string
+	for _, m := range messages {
+		parts = append(parts, m.Content)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func TestFitMessages_FitsUnchanged(t *testing.T) {
+	messages := conversation()
+	out, report := contextpkg.FitMessages(messages, contextpkg.Budget{ContextLength: tokens(messages...)})
+	if report.Trimmed() || !reflect.DeepEqual(out, messages) {
+		t.Errorf("Expected the context unchanged, got %s", report.Summary())
+	}
+}
+
+func TestFitMessages_TrimmingOrder(t *testing.T) {
+	messages := conversation()
+	total := tokens(messages...)
+	logs := tokens(messages[1:4]...)
+	history := tokens(messages[4:8]...)
+	snapshot := tokens(messages[8])
+	final := messages[9]
+
+	t.Run("DCE logs first", func(t *testing.T) {
+		out, report := contextpkg.FitMessages(messages, contextpkg.Budget{ContextLength: total - logs})
+		if len(out) != len(messages)-3 || strings.Contains(contents(out), "[DCE]") {
+			t.Errorf("Expected only the DCE logs dropped, got %d messages", len(out))
+		}
+		if len(report.Dropped) != 1 || !strings.Contains(report.Dropped[0], "DCE log") {
+			t.Errorf("unexpected report %v", report.Dropped)
+		}
+	})
+
+	t.Run("then the oldest history", func(t *testing.T) {
+		out, report := contextpkg.FitMessages(messages, contextpkg.Budget{ContextLength: total - logs - tokens(messages[4:6]...)})
+		text := contents(out)
+		if strings.Contains(text, "history 0") || strings.Contains(text, "history 1") {
+			t.Error("Expected the two oldest history messages dropped")
+		}
+		if !strings.Contains(text, "history 2") || !strings.Contains(text, "history 3") {
+			t.Error("Expected the newer history kept")
+		}
+		if out[len(out)-2].Content != messages[8].Content {
+			t.Error("Expected the DCE snapshot untouched")
+		}
+		if len(report.Dropped) != 2 || !strings.Contains(report.Dropped[1], "oldest chat") {
+			t.Errorf("unexpected report %v", report.Dropped)
+		}
+	})
+
+	t.Run("then the snapshot", func(t *testing.T) {
+		out, report := contextpkg.FitMessages(messages, contextpkg.Budget{ContextLength: total - logs - history - snapshot/2})
+		if len(out) != 3 || out[0].Content != messages[0].Content {
+			t.Fatalf("Expected system prompt, snapshot and final message, got %d messages", len(out))
+		}
+		if tokens(out[1]) >= snapshot || !strings.Contains(out[1].Content, "truncated") {
+			t.Errorf("Expected the snapshot condensed, got ~%d tokens", tokens(out[1]))
+		}
+		if out[2].Content != final.Content {
+			t.Error("Expected the final message untouched")
+		}
+		if !strings.Contains(report.Dropped[len(report.Dropped)-1], "condensed DCE snapshot") {
+			t.Errorf("unexpected report %v", report.Dropped)
+		}
+	})
+
+	t.Run("then the largest message", func(t *testing.T) {
+		out, report := contextpkg.FitMessages(messages, contextpkg.Budget{ContextLength: 1000})
+		last := out[len(out)-1]
+		if last.Role != "user" || !strings.HasPrefix(last.Content, "diff line 000") || !strings.Contains(last.Content, "truncated") {
+			t.Errorf("Expected the final message truncated, got %q...", last.Content[:40])
+		}
+		if !strings.Contains(report.Dropped[len(report.Dropped)-1], "truncated user message") {
+			t.Errorf("unexpected report %v", report.Dropped)
+		}
+		if report.After > report.Limit || report.Before != total {
+			t.Errorf("report Before/After = %d/%d, limit %d", report.Before, report.After, report.Limit)
+		}
+	})
+}
+
+func TestFitMessages_KeepsFinalMessage(t *testing.T) {
+	messages := []contextpkg.Message{
+		{Role: "user", Content: strings.Repeat("old ", 200)},
+		{Role: "assistant", Content: strings.Repeat("reply ", 200)},
+		{Role: "user", Content: "the question"},
+	}
+	out, _ := contextpkg.FitMessages(messages, contextpkg.Budget{ContextLength: 10})
+	if len(out) == 0 || out[len(out)-1].Content != "the question" {
+		t.Errorf("Expected the final message kept, got %+v", out)
+	}
+}
+
+func TestFitMessages_DoesNotMutateInput(t *testing.T) {
+	messages := conversation()
+	original := append([]contextpkg.Message(nil), messages...)
+
+	contextpkg.FitMessages(messages, contextpkg.Budget{ContextLength: 1000})
+	if !reflect.DeepEqual(messages, original) {
+		t.Error("FitMessages modified its input")
+	}
+}
+
+func TestTruncateToTokens(t *testing.T) {
+	text := numberedLines("diff", 100)
+	out := contextpkg.TruncateToTokens(text, 200)
+
+	lines := strings.Split(out, "\n")
+	kept := len(lines) - 1
+	if kept == 0 || lines[0] != "diff line 000 with some padding text" {
+		t.Fatalf("Expected whole lines kept, got %q", lines[0])
+	}
+	if want := fmt.Sprintf("[... %d lines truncated to fit the model context ...]", 100-kept); lines[kept] != want {
+		t.Errorf("marker = %q, want %q", lines[kept], want)
+	}
+	if got := contextpkg.TruncateToTokens("short", 200); got != "short" {
+		t.Errorf("Expected short text unchanged, got %q", got)
+	}
+}
+
+func TestTruncateToTokens_CutsHugeFirstLine(t *testing.T) {
+	text := strings.Repeat("x", 10000) + "\nsecond\nthird"
+	out := contextpkg.TruncateToTokens(text, 100)
+
+	lines := strings.Split(out, "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "xxx") || len(lines[0]) >= 10000 {
+		t.Fatalf("Expected the first line cut, got %d lines", len(lines))
+	}
+	if want := "[... line cut and 2 more lines truncated to fit the model context ...]"; lines[1] != want {
+		t.Errorf("marker = %q, want %q", lines[1], want)
+	}
+}
@@ -0,0 +1,90 @@
+// test/contextpkg/diff_condenser/diff_condenser_test.go
+package diff_condenser_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+)
+
+// fileDiff builds a git-style diff for path with the given number of hunks,
+// each adding linesPerHunk lines.
+func fileDiff(path string, hunks, linesPerHunk int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\nindex 1111111..2222222 100644\n--- a/%s\n+++ b/%s\n", path, path, path, path)
+	for h := 0; h < hunks; h++ {
+		fmt.Fprintf(&b, "@@ -%d,1 +%d,%d @@\n", h*100+1, h*100+1, linesPerHunk+1)
+		b.WriteString(" context line\n")
+		for l := 0; l < linesPerHunk; l++ {
+			fmt.Fprintf(&b, "+%s hunk %d line %d with some extra text to cost tokens\n", path, h, l)
+		}
+	}
+	return b.String()
+}
+
+func TestParseUnifiedDiff_CountsFilesAndHunks(t *testing.T) {
+	diff := "--- Staged Changes ---\n" + fileDiff("a.go", 2, 3) + fileDiff("b.go", 1, 1)
+
+	files, other := contextpkg.ParseUnifiedDiff(diff)
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+	if files[0].Path != "a.go" || len(files[0].Hunks) != 2 || files[0].Added != 6 {
+		t.Errorf("Unexpected a.go parse: path=%s hunks=%d added=%d", files[0].Path, len(files[0].Hunks), files[0].Added)
+	}
+	if len(other) != 1 || other[0] != "--- Staged Changes ---" {
+		t.Errorf("Expected the section banner as other content, got %v", other)
+	}
+}
+
+func TestCondenseDiff_FitsUnchangedWhenSmall(t *testing.T) {
+	diff := fileDiff("main.go", 1, 2)
+	result := contextpkg.CondenseDiff(diff, 10000)
+
+	if result.OmittedHunks != 0 {
+		t.Errorf("Expected no omitted hunks, got %d", result.OmittedHunks)
+	}
+	if !strings.HasPrefix(result.Text, "Changed files (1 files, +2 -0):") {
+		t.Errorf("Expected stat header first, got:\n%s", result.Text)
+	}
+	if !strings.Contains(result.Text, "+main.go hunk 0 line 1") {
+		t.Errorf("Expected hunk body to be kept, got:\n%s", result.Text)
+	}
+}
+
+func TestCondenseDiff_SkipsLockfiles(t *testing.T) {
+	diff := fileDiff("go.sum", 1, 50) + fileDiff("cmd/root.go", 1, 2)
+	result := contextpkg.CondenseDiff(diff, 10000)
+
+	if len(result.SkippedFiles) != 1 || result.SkippedFiles[0] != "go.sum" {
+		t.Fatalf("Expected go.sum to be skipped, got %v", result.SkippedFiles)
+	}
+	if !strings.Contains(result.Text, "go.sum | +50 -0 (lockfile, contents omitted)") {
+		t.Errorf("Expected lockfile listed in stat header, got:\n%s", result.Text)
+	}
+	if strings.Contains(result.Text, "+go.sum hunk") {
+		t.Error("Lockfile contents should not be included")
+	}
+}
+
+func TestCondenseDiff_KeepsWholeHunksAcrossFiles(t *testing.T) {
+	// Each hunk costs roughly 300 tokens; a 1000 token budget can't hold all six
+	diff := fileDiff("first.go", 3, 18) + fileDiff("second.go", 3, 18)
+	result := contextpkg.CondenseDiff(diff, 1000)
+
+	if result.OmittedHunks == 0 {
+		t.Fatal("Expected some hunks to be omitted")
+	}
+	// Round-robin: the second file gets its first hunk before the first file gets its second
+	if !strings.Contains(result.Text, "+second.go hunk 0 line 17") {
+		t.Errorf("Expected second.go's first hunk to be kept whole, got:\n%s", result.Text)
+	}
+	if !strings.Contains(result.Text, "more hunks omitted in first.go") {
+		t.Errorf("Expected an omission note for first.go, got:\n%s", result.Text)
+	}
+	if strings.Contains(result.Text, "lines truncated") {
+		t.Errorf("Whole hunks should fit without line truncation, got:\n%s", result.Text)
+	}
+}
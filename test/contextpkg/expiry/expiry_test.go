@@ -0,0 +1,108 @@
+// test/contextpkg/expiry/expiry_test.go
+package expiry_test
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+)
+
+// startConversation adds a conversation last active age ago with the given messages.
+func startConversation(cm *contextpkg.ConversationManager, id string, age time.Duration, messages ...string) *contextpkg.Conversation {
+	conv := cm.StartConversation(id, "", false)
+	for _, content := range messages {
+		conv.AddMessage("user", content)
+	}
+	conv.LastActivity = time.Now().Add(-age)
+	return conv
+}
+
+func remaining(cm *contextpkg.ConversationManager) string {
+	var ids []string
+	for _, snap := range cm.Snapshots() {
+		ids = append(ids, snap.ID)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+func TestExpireIdle(t *testing.T) {
+	cm := contextpkg.NewConversationManager()
+	startConversation(cm, "idle", 2*time.Hour, "hello")
+	startConversation(cm, "recent", time.Minute, "hello")
+
+	evicted := cm.Expire(contextpkg.ExpiryPolicy{MaxIdle: time.Hour}, nil)
+	if len(evicted) != 1 || evicted[0].Snapshot.ID != "idle" || !strings.Contains(evicted[0].Reason, "idle") {
+		t.Errorf("unexpected evictions %+v", evicted)
+	}
+	if got := remaining(cm); got != "recent" {
+		t.Errorf("remaining = %q", got)
+	}
+
+	// Cleanup applies the same rule
+	cm.Cleanup(30 * time.Second)
+	if got := remaining(cm); got != "" {
+		t.Errorf("remaining after Cleanup = %q", got)
+	}
+}
+
+func TestExpireBudgetsOldestFirst(t *testing.T) {
+	cm := contextpkg.NewConversationManager()
+	startConversation(cm, "oldest", 3*time.Minute, "a", "b")
+	startConversation(cm, "older", 2*time.Minute, "c", "d")
+	startConversation(cm, "newest", time.Minute, "e", "f")
+
+	evicted := cm.Expire(contextpkg.ExpiryPolicy{MaxMessages: 4}, nil)
+	if len(evicted) != 1 || evicted[0].Snapshot.ID != "oldest" || len(evicted[0].Snapshot.Messages) != 2 {
+		t.Errorf("unexpected evictions %+v", evicted)
+	}
+	if got := remaining(cm); got != "newest,older" {
+		t.Errorf("remaining = %q", got)
+	}
+
+	evicted = cm.Expire(contextpkg.ExpiryPolicy{MaxBytes: 1}, nil)
+	if len(evicted) != 2 || evicted[0].Snapshot.ID != "older" || !strings.Contains(evicted[1].Reason, "bytes") {
+		t.Errorf("unexpected evictions %+v", evicted)
+	}
+}
+
+func TestExpireKeepsWhatBeforeEvictFailsOn(t *testing.T) {
+	cm := contextpkg.NewConversationManager()
+	startConversation(cm, "unsaved", 2*time.Hour, "hello")
+	startConversation(cm, "saved", 2*time.Hour, "hello")
+
+	var seen []string
+	evicted := cm.Expire(contextpkg.ExpiryPolicy{MaxIdle: time.Hour}, func(snap contextpkg.ConversationSnapshot) error {
+		seen = append(seen, snap.ID)
+		if snap.ID == "unsaved" {
+			return errors.New("disk full")
+		}
+		return nil
+	})
+	if len(seen) != 2 {
+		t.Errorf("Expected beforeEvict for both conversations, got %v", seen)
+	}
+	if len(evicted) != 1 || evicted[0].Snapshot.ID != "saved" {
+		t.Errorf("unexpected evictions %+v", evicted)
+	}
+	if got := remaining(cm); got != "unsaved" {
+		t.Errorf("remaining = %q", got)
+	}
+}
+
+func TestExpireKeepsConversationsActiveMeanwhile(t *testing.T) {
+	cm := contextpkg.NewConversationManager()
+	conv := startConversation(cm, "busy", 2*time.Hour)
+
+	evicted := cm.Expire(contextpkg.ExpiryPolicy{MaxIdle: time.Hour}, func(contextpkg.ConversationSnapshot) error {
+		conv.AddMessage("user", "still here")
+		return nil
+	})
+	if len(evicted) != 0 || remaining(cm) != "busy" {
+		t.Errorf("Expected busy to be kept, evicted %+v", evicted)
+	}
+}
@@ -0,0 +1,104 @@
+// test/contextpkg/snapshot/snapshot_test.go
+package snapshot_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+)
+
+func TestSnapshotIsACopy(t *testing.T) {
+	cm := contextpkg.NewConversationManager()
+	conv := cm.StartConversation("conv-1", "", false)
+	conv.AddMessage("user", "hello")
+
+	snap, ok := cm.Snapshot("conv-1")
+	if !ok {
+		t.Fatal("Expected a snapshot of conv-1")
+	}
+	conv.AddMessage("assistant", "hi")
+	snap.Messages[0].Content = "changed"
+
+	if len(snap.Messages) != 1 {
+		t.Errorf("Expected the snapshot to keep 1 message, got %d", len(snap.Messages))
+	}
+	if conv.Messages[0].Content != "hello" {
+		t.Errorf("Changing the snapshot changed the conversation: %q", conv.Messages[0].Content)
+	}
+	if _, ok := cm.Snapshot("missing"); ok {
+		t.Error("Expected no snapshot of a missing conversation")
+	}
+}
+
+func TestSnapshotsMostRecentFirst(t *testing.T) {
+	cm := contextpkg.NewConversationManager()
+	cm.StartConversation("old", "", false).LastActivity = time.Now().Add(-time.Hour)
+	cm.StartConversation("new", "", false)
+
+	snaps := cm.Snapshots()
+	if len(snaps) != 2 || snaps[0].ID != "new" || snaps[1].ID != "old" {
+		t.Errorf("unexpected order %v", snaps)
+	}
+}
+
+func TestRenameAndSetTags(t *testing.T) {
+	cm := contextpkg.NewConversationManager()
+	cm.StartConversation("conv-1", "", false)
+
+	if !cm.Rename("conv-1", "  Parser refactor ") || !cm.SetTags("conv-1", []string{"parser", " ", "review", "parser"}) {
+		t.Fatal("Expected conv-1 to be updated")
+	}
+	if cm.Rename("missing", "x") || cm.SetTags("missing", nil) {
+		t.Error("Expected updates of a missing conversation to fail")
+	}
+
+	snap, _ := cm.Snapshot("conv-1")
+	if snap.Title != "Parser refactor" {
+		t.Errorf("Title = %q", snap.Title)
+	}
+	if strings.Join(snap.Tags, ",") != "parser,review" {
+		t.Errorf("Tags = %v", snap.Tags)
+	}
+	if !snap.HasTag("review") || snap.HasTag("other") {
+		t.Errorf("HasTag is wrong for %v", snap.Tags)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	snap := contextpkg.ConversationSnapshot{
+		ID:           "conv-1",
+		Title:        "Parser refactor",
+		Tags:         []string{"parser"},
+		LastActivity: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Tasks:        []contextpkg.Task{{Description: "Split the lexer"}},
+		Messages: []contextpkg.Message{
+			{Role: "user", Content: "How do I split it?"},
+			{Role: "assistant", Content: "Move the lexer out.\n"},
+		},
+	}
+	md := snap.Markdown()
+
+	for _, want := range []string{
+		"# Parser refactor\n",
+		"- ID: `conv-1`\n",
+		"- Tags: parser\n",
+		"- Last activity: 2025-03-01T12:00:00Z\n",
+		"## Tasks\n\n- Split the lexer\n",
+		"## User\n\nHow do I split it?\n",
+		"## Assistant\n\nMove the lexer out.\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected %q in:\n%s", want, md)
+		}
+	}
+	if strings.Index(md, "## User") > strings.Index(md, "## Assistant") {
+		t.Error("Expected the messages in order")
+	}
+
+	snap.Title = ""
+	if !strings.HasPrefix(snap.Markdown(), "# conv-1\n") {
+		t.Error("Expected the ID as the title of an untitled conversation")
+	}
+}
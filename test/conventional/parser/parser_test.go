@@ -0,0 +1,66 @@
+// test/conventional/parser/parser_test.go
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/conventional"
+)
+
+func TestParse(t *testing.T) {
+	c, ok := conventional.Parse("feat(dce)!: add task refresh\n\nLonger body.\n\nBREAKING CHANGE: tasks are now\nreloaded on every turn\nRefs: #12")
+	if !ok {
+		t.Fatal("Expected a conventional commit")
+	}
+	if c.Type != "feat" || c.Scope != "dce" || !c.Breaking || c.Subject != "add task refresh" {
+		t.Errorf("Unexpected header fields: %+v", c)
+	}
+	if want := []string{"tasks are now reloaded on every turn"}; !reflect.DeepEqual(c.BreakingNotes, want) {
+		t.Errorf("Expected breaking notes %v, got %v", want, c.BreakingNotes)
+	}
+
+	if _, ok := conventional.Parse("Update README"); ok {
+		t.Error("Plain subject should not parse as conventional")
+	}
+	if c, _ := conventional.Parse("fix: typo\n\nBREAKING-CHANGE: renamed flag"); !c.Breaking {
+		t.Error("BREAKING-CHANGE footer should mark the commit breaking")
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	a := conventional.Analyze([]string{
+		"fix(dce): handle empty task list",
+		"feat(dce): add task refresh",
+		"docs: describe refresh",
+		"Merge branch 'main'",
+	})
+	if a.Type != "feat" || a.Scope != "dce" || a.Breaking {
+		t.Errorf("Unexpected analysis: type=%s scope=%s breaking=%v", a.Type, a.Scope, a.Breaking)
+	}
+	if got := a.Title("Add task refresh to DCE"); got != "feat(dce): Add task refresh to DCE" {
+		t.Errorf("Unexpected title %q", got)
+	}
+	if got := a.Title("fix: something"); got != "feat(dce): something" {
+		t.Errorf("Existing prefix should be replaced, got %q", got)
+	}
+	if want := []string{"bug", "documentation", "enhancement"}; !reflect.DeepEqual(a.Labels(), want) {
+		t.Errorf("Expected labels %v, got %v", want, a.Labels())
+	}
+}
+
+func TestAnalyze_MixedScopesAndBreaking(t *testing.T) {
+	a := conventional.Analyze([]string{"feat(api): one", "feat(cli)!: two"})
+	if a.Scope != "" || !a.Breaking {
+		t.Errorf("Expected no shared scope and breaking, got scope=%q breaking=%v", a.Scope, a.Breaking)
+	}
+	if got := a.Title("Two things"); got != "feat!: Two things" {
+		t.Errorf("Unexpected title %q", got)
+	}
+	if got := a.BreakingCallout(); got != "> [!WARNING]\n> **BREAKING CHANGE**: two" {
+		t.Errorf("Unexpected callout %q", got)
+	}
+	if conventional.Analyze([]string{"WIP"}).Conventional() {
+		t.Error("Non-conventional messages should produce an empty analysis")
+	}
+}
@@ -0,0 +1,181 @@
+// test/forge/gitlab/gitlab_test.go
+package gitlab_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/forge"
+)
+
+// fakeGitLab records the requests made to it and answers like the GitLab API.
+type fakeGitLab struct {
+	mu       sync.Mutex
+	requests map[string]map[string]interface{}
+}
+
+func (f *fakeGitLab) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	// The project path must reach the server escaped, as one path segment
+	path := r.URL.EscapedPath()
+	f.requests[r.Method+" "+path] = body
+
+	switch r.Method + " " + path {
+	case "GET /api/v4/projects/group%2Fsub%2Fwidgets":
+		w.Write([]byte(`{"default_branch": "develop"}`))
+	case "GET /api/v4/projects/group%2Fsub%2Fwidgets/labels":
+		w.Write([]byte(`[{"name": "bug"}, {"name": "feature"}]`))
+	case "GET /api/v4/users":
+		switch r.URL.Query().Get("username") {
+		case "alice":
+			w.Write([]byte(`[{"id": 11}]`))
+		case "bob":
+			w.Write([]byte(`[{"id": 12}]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	case "GET /api/v4/projects/group%2Fsub%2Fwidgets/merge_requests":
+		if r.URL.Query().Get("source_branch") == "feature" && r.URL.Query().Get("state") == "opened" {
+			w.Write([]byte(`[{"iid": 4, "web_url": "https://gitlab.example.com/group/sub/widgets/-/merge_requests/4", "title": "Draft: t", "description": "old"}]`))
+		} else {
+			w.Write([]byte(`[]`))
+		}
+	case "GET /api/v4/projects/group%2Fsub%2Fwidgets/merge_requests/4":
+		w.Write([]byte(`{"iid": 4, "title": "Draft: t"}`))
+	case "PUT /api/v4/projects/group%2Fsub%2Fwidgets/merge_requests/4":
+		w.Write([]byte(`{"iid": 4}`))
+	case "POST /api/v4/projects/group%2Fsub%2Fwidgets/merge_requests":
+		if body["source_branch"] == "exists" {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"message": ["Another open merge request already exists for this source branch: !3"]}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"iid": 4, "web_url": "https://gitlab.example.com/group/sub/widgets/-/merge_requests/4"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "404 Not Found"}`))
+	}
+}
+
+func newClient(t *testing.T) (*forge.GitLab, *fakeGitLab) {
+	t.Helper()
+	fake := &fakeGitLab{requests: make(map[string]map[string]interface{})}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := forge.NewGitLab(forge.Remote{Host: "gitlab.example.com", Owner: "group/sub", Repo: "widgets"}, "secret")
+	client.BaseURL = server.URL + "/api/v4"
+	return client, fake
+}
+
+func TestFromRemote_DetectsForge(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "gl")
+	t.Setenv("GITHUB_TOKEN", "gh")
+	t.Setenv("GITLAB_HOST", "https://code.example.com")
+
+	for host, want := range map[string]string{
+		"github.com":       "GitHub",
+		"gitlab.com":       "GitLab",
+		"code.example.com": "GitLab",
+	} {
+		f, err := forge.FromRemote(forge.Remote{Host: host, Owner: "o", Repo: "r"})
+		if err != nil || f.Name() != want {
+			t.Errorf("FromRemote(%s) = %v, %v; want %s", host, f, err, want)
+		}
+	}
+	if _, err := forge.FromRemote(forge.Remote{Host: "bitbucket.org", Owner: "o", Repo: "r"}); err == nil {
+		t.Error("Expected an error for an unsupported host")
+	}
+}
+
+func TestGitLab_DefaultBranchAndLabels(t *testing.T) {
+	client, _ := newClient(t)
+
+	branch, err := client.DefaultBranch(context.Background())
+	if err != nil || branch != "develop" {
+		t.Errorf("DefaultBranch = %q, %v; want develop", branch, err)
+	}
+	labels, err := client.Labels(context.Background())
+	if err != nil || !reflect.DeepEqual(labels, []string{"bug", "feature"}) {
+		t.Errorf("Labels = %v, %v", labels, err)
+	}
+}
+
+func TestGitLab_CreateMergeRequest(t *testing.T) {
+	client, fake := newClient(t)
+
+	mr, err := client.CreatePullRequest(context.Background(), forge.PullRequestOptions{
+		Title:     "feat: widgets",
+		Body:      "# feat: widgets\n\nBody",
+		Head:      "feature",
+		Base:      "develop",
+		Draft:     true,
+		Assignees: []string{"alice"},
+		Reviewers: []string{"@bob", "nobody"},
+		Labels:    []string{"feature", "bug"},
+	})
+	if mr == nil || mr.Number != 4 || !strings.HasSuffix(mr.URL, "/merge_requests/4") {
+		t.Fatalf("CreatePullRequest = %+v, %v", mr, err)
+	}
+	if err == nil || !strings.Contains(err.Error(), `reviewers: unknown user "nobody"`) {
+		t.Errorf("Expected the unknown reviewer to be reported, got %v", err)
+	}
+
+	got := fake.requests["POST /api/v4/projects/group%2Fsub%2Fwidgets/merge_requests"]
+	want := map[string]interface{}{
+		"source_branch": "feature",
+		"target_branch": "develop",
+		"title":         "Draft: feat: widgets",
+		"description":   "# feat: widgets\n\nBody",
+		"assignee_ids":  []interface{}{float64(11)},
+		"reviewer_ids":  []interface{}{float64(12)},
+		"labels":        "feature,bug",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merge request payload = %v, want %v", got, want)
+	}
+}
+
+func TestGitLab_FindAndUpdateMergeRequest(t *testing.T) {
+	client, fake := newClient(t)
+
+	mr, err := client.FindPullRequest(context.Background(), "feature")
+	if err != nil || mr == nil || mr.Number != 4 || mr.Body != "old" {
+		t.Fatalf("FindPullRequest = %+v, %v", mr, err)
+	}
+	if mr, err := client.FindPullRequest(context.Background(), "other"); err != nil || mr != nil {
+		t.Errorf("Expected no merge request for another branch, got %+v, %v", mr, err)
+	}
+
+	// A draft merge request stays a draft
+	if err := client.UpdatePullRequest(context.Background(), 4, "New title", "new"); err != nil {
+		t.Fatalf("UpdatePullRequest: %v", err)
+	}
+	want := map[string]interface{}{"title": "Draft: New title", "description": "new"}
+	if got := fake.requests["PUT /api/v4/projects/group%2Fsub%2Fwidgets/merge_requests/4"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("update payload = %v, want %v", got, want)
+	}
+}
+
+func TestGitLab_CreateMergeRequestError(t *testing.T) {
+	client, _ := newClient(t)
+
+	mr, err := client.CreatePullRequest(context.Background(), forge.PullRequestOptions{Title: "WIP: t", Head: "exists", Base: "develop", Draft: true})
+	if mr != nil || err == nil {
+		t.Fatalf("Expected an error, got %+v, %v", mr, err)
+	}
+	if !strings.Contains(err.Error(), "GitLab API 409: Another open merge request already exists") {
+		t.Errorf("Expected GitLab's message, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+// test/llm/api/api_test.go
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+type echoClient struct{}
+
+func (echoClient) GetChatResponse(_ context.Context, messages []contextpkg.Message) (string, error) {
+	return "echo: " + messages[len(messages)-1].Content, nil
+}
+
+func (c echoClient) StreamChatResponse(ctx context.Context, messages []contextpkg.Message) (<-chan string, error) {
+	resp, _ := c.GetChatResponse(ctx, messages)
+	ch := make(chan string, 1)
+	ch <- resp
+	close(ch)
+	return ch, nil
+}
+
+func request(t *testing.T, method, path, body string) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	llm.NewRouter().ServeHTTP(rec, req)
+
+	var decoded map[string]any
+	if strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+			t.Fatalf("%s %s: invalid JSON %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec, decoded
+}
+
+func TestHealthAndInfo(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	contextpkg.SetActiveModel("test-model")
+
+	rec, body := request(t, http.MethodGet, "/v1/health", "")
+	if rec.Code != http.StatusOK || body["status"] != "ok" {
+		t.Errorf("health = %d %v", rec.Code, body)
+	}
+
+	rec, body = request(t, http.MethodGet, "/v1/info", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("info = %d %s", rec.Code, rec.Body.String())
+	}
+	if body["apiVersion"] != "v1" || body["activeModel"] != "test-model" || body["version"] == "" {
+		t.Errorf("unexpected info %v", body)
+	}
+	if repo, _ := body["repoPath"].(string); repo == "" {
+		t.Errorf("Expected the repository path, got %v", body["repoPath"])
+	}
+	for _, key := range []string{"uptimeSeconds", "openConversations", "startedAt", "provider"} {
+		if _, ok := body[key]; !ok {
+			t.Errorf("info is missing %q", key)
+		}
+	}
+}
+
+func TestStructuredErrors(t *testing.T) {
+	tests := []struct {
+		method, path, body string
+		status             int
+		code               string
+	}{
+		{http.MethodGet, "/v1/nope", "", http.StatusNotFound, llm.CodeNotFound},
+		{http.MethodPost, "/v1/health", "", http.StatusMethodNotAllowed, llm.CodeMethodNotAllowed},
+		{http.MethodPost, "/v1/quickassist", "{not json", http.StatusBadRequest, llm.CodeInvalidRequest},
+		{http.MethodPost, "/v1/quickassist", `{"input": ""}`, http.StatusBadRequest, llm.CodeInvalidRequest},
+		{http.MethodGet, "/v1/drafts?branch=main", "", http.StatusBadRequest, llm.CodeInvalidRequest},
+		{http.MethodPut, "/v1/model", `{}`, http.StatusBadRequest, llm.CodeInvalidRequest},
+		// The unversioned routes report the same codes
+		{http.MethodGet, "/quickassist", "", http.StatusMethodNotAllowed, llm.CodeMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		rec, body := request(t, tt.method, tt.path, tt.body)
+		if rec.Code != tt.status || body["code"] != tt.code || body["error"] == "" {
+			t.Errorf("%s %s = %d %v, want %d %s", tt.method, tt.path, rec.Code, body, tt.status, tt.code)
+		}
+	}
+
+	rec, _ := request(t, http.MethodDelete, "/v1/model", "")
+	if allow := rec.Header().Get("Allow"); allow != "GET, PUT" {
+		t.Errorf("Allow = %q, want GET, PUT", allow)
+	}
+}
+
+func TestQuickAssistAndModel(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	llm.SetLLMClient(echoClient{})
+
+	rec, body := request(t, http.MethodPost, "/v1/quickassist", `{"input": "hi"}`)
+	if rec.Code != http.StatusOK || body["response"] != "echo: hi" || body["conversationId"] == "" {
+		t.Errorf("quickassist = %d %v", rec.Code, body)
+	}
+
+	if rec, body := request(t, http.MethodPut, "/v1/model", `{"model": "other"}`); rec.Code != http.StatusOK || body["activeModel"] != "other" {
+		t.Errorf("PUT model = %d %v", rec.Code, body)
+	}
+	if rec, body := request(t, http.MethodGet, "/v1/model", ""); rec.Code != http.StatusOK || body["activeModel"] != "other" {
+		t.Errorf("GET model = %d %v", rec.Code, body)
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	rec, doc := request(t, http.MethodGet, "/v1/openapi.json", "")
+	if rec.Code != http.StatusOK || doc["openapi"] != "3.0.3" {
+		t.Fatalf("openapi.json = %d %v", rec.Code, doc["openapi"])
+	}
+
+	paths := doc["paths"].(map[string]any)
+	for path, method := range map[string]string{
+		"/v1/health": "get", "/v1/info": "get", "/v1/quickassist": "post",
+		"/v1/quickassist/stream": "post", "/v1/drafts": "get", "/v1/model": "put",
+	} {
+		ops, _ := paths[path].(map[string]any)
+		if _, ok := ops[method]; !ok {
+			t.Errorf("Expected %s %s in the document", method, path)
+		}
+	}
+
+	// Query parameters of GET routes come from the request type
+	params := paths["/v1/drafts"].(map[string]any)["get"].(map[string]any)["parameters"].([]any)
+	if len(params) != 2 || params[0].(map[string]any)["name"] != "branch" || params[0].(map[string]any)["in"] != "query" {
+		t.Errorf("unexpected /v1/drafts parameters %v", params)
+	}
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	info := schemas["InfoResponse"].(map[string]any)["properties"].(map[string]any)
+	if info["startedAt"].(map[string]any)["format"] != "date-time" || info["uptimeSeconds"].(map[string]any)["type"] != "integer" {
+		t.Errorf("unexpected InfoResponse schema %v", info)
+	}
+	required := schemas["QuickAssistRequest"].(map[string]any)["required"].([]any)
+	if len(required) != 1 || required[0] != "input" {
+		t.Errorf("Expected only input to be required, got %v", required)
+	}
+	codes := schemas["APIError"].(map[string]any)["properties"].(map[string]any)["code"].(map[string]any)["enum"].([]any)
+	if len(codes) == 0 {
+		t.Error("Expected the error codes to be listed")
+	}
+}
@@ -0,0 +1,98 @@
+// test/llm/auth/auth_test.go
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+)
+
+const token = "s3cret"
+
+func handler() http.Handler {
+	return llm.RequireAuth(llm.AuthConfig{
+		Token:          token,
+		Port:           4242,
+		AllowedOrigins: []string{"vscode-webview://*", "http://localhost:3000"},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+}
+
+func TestRequireAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		host    string
+		headers map[string]string
+		want    int
+	}{
+		{"valid token", "POST", "localhost:4242", map[string]string{"Authorization": "Bearer " + token}, http.StatusOK},
+		{"loopback IP", "POST", "127.0.0.1:4242", map[string]string{"Authorization": "Bearer " + token}, http.StatusOK},
+		{"IPv6 loopback", "POST", "[::1]:4242", map[string]string{"Authorization": "Bearer " + token}, http.StatusOK},
+		{"no token", "POST", "localhost:4242", nil, http.StatusUnauthorized},
+		{"wrong token", "POST", "localhost:4242", map[string]string{"Authorization": "Bearer nope"}, http.StatusUnauthorized},
+		{"rebound host", "POST", "evil.example.com:4242", map[string]string{"Authorization": "Bearer " + token}, http.StatusMisdirectedRequest},
+		{"other port", "POST", "localhost:80", map[string]string{"Authorization": "Bearer " + token}, http.StatusMisdirectedRequest},
+		{"foreign origin", "POST", "localhost:4242", map[string]string{"Authorization": "Bearer " + token, "Origin": "https://evil.example.com"}, http.StatusForbidden},
+		{"browser without origin", "POST", "localhost:4242", map[string]string{"Authorization": "Bearer " + token, "Sec-Fetch-Site": "cross-site"}, http.StatusForbidden},
+		{"allowed origin", "POST", "localhost:4242", map[string]string{"Authorization": "Bearer " + token, "Origin": "vscode-webview://abc"}, http.StatusOK},
+		{"allowed origin without token", "POST", "localhost:4242", map[string]string{"Origin": "http://localhost:3000"}, http.StatusUnauthorized},
+		{"preflight", "OPTIONS", "localhost:4242", map[string]string{"Origin": "http://localhost:3000"}, http.StatusNoContent},
+		{"foreign preflight", "OPTIONS", "localhost:4242", map[string]string{"Origin": "http://localhost:3001"}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/quickassist", nil)
+			req.Host = tt.host
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			handler().ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+			if origin := tt.headers["Origin"]; rec.Code < 400 && origin != "" && rec.Header().Get("Access-Control-Allow-Origin") != origin {
+				t.Errorf("Expected the allowed origin to be echoed, got %q", rec.Header().Get("Access-Control-Allow-Origin"))
+			}
+		})
+	}
+}
+
+func TestTokenFile(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	tok, err := llm.NewSessionToken()
+	if err != nil || len(tok) != 64 {
+		t.Fatalf("NewSessionToken = %q, %v", tok, err)
+	}
+	if other, _ := llm.NewSessionToken(); other == tok {
+		t.Error("Expected a new token each time")
+	}
+
+	if err := utils.WriteTokenFile(tok); err != nil {
+		t.Fatalf("WriteTokenFile: %v", err)
+	}
+	cacheDir, _ := os.UserCacheDir()
+	info, err := os.Stat(filepath.Join(cacheDir, "prbuddy-go", "token"))
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected a 0600 token file, got %v, %v", info, err)
+	}
+	if got, err := utils.ReadTokenFile(); err != nil || got != tok {
+		t.Errorf("ReadTokenFile = %q, %v", got, err)
+	}
+
+	if err := utils.DeleteTokenFile(); err != nil {
+		t.Fatalf("DeleteTokenFile: %v", err)
+	}
+	if _, err := utils.ReadTokenFile(); err == nil {
+		t.Error("Expected no token after deletion")
+	}
+}
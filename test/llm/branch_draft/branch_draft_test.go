@@ -0,0 +1,75 @@
+// test/llm/branch_draft/branch_draft_test.go
+package branch_draft_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+func commitFile(t *testing.T, path, content, message string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	if _, err := utils.ExecGit("add", path); err != nil {
+		t.Fatalf("Failed to add %s: %v", path, err)
+	}
+	if _, err := utils.ExecGit("commit", "-m", message); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+}
+
+func TestBranchDraftInput_CoversEveryCommitSinceMergeBase(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	if _, err := utils.ExecGit("branch", "-M", "main"); err != nil {
+		t.Fatalf("Failed to rename branch: %v", err)
+	}
+	if _, err := utils.ExecGit("checkout", "-b", "feature"); err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+	commitFile(t, "first.txt", "one\n", "Add first file")
+	commitFile(t, "second.txt", "two\n", "Add second file\n\nWith a body.")
+
+	input, err := llm.BranchDraftInput("main")
+	if err != nil {
+		t.Fatalf("BranchDraftInput failed: %v", err)
+	}
+
+	if input.Commits != 2 || input.Base != "main" || input.MergeBase == "" {
+		t.Errorf("Unexpected branch info: commits=%d base=%q mergeBase=%q", input.Commits, input.Base, input.MergeBase)
+	}
+	for _, want := range []string{"Add first file", "Add second file", "With a body."} {
+		if !strings.Contains(input.CommitMessage, want) {
+			t.Errorf("Expected commit messages to contain %q, got:\n%s", want, input.CommitMessage)
+		}
+	}
+	if strings.Index(input.CommitMessage, "Add first file") > strings.Index(input.CommitMessage, "Add second file") {
+		t.Error("Expected commits oldest first")
+	}
+	if !strings.Contains(input.Diff, "b/first.txt") || !strings.Contains(input.Diff, "b/second.txt") {
+		t.Errorf("Expected cumulative diff of both commits, got:\n%s", input.Diff)
+	}
+}
+
+func TestBranchDraftInput_FailsWithoutCommits(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	if _, err := utils.ExecGit("branch", "-M", "main"); err != nil {
+		t.Fatalf("Failed to rename branch: %v", err)
+	}
+	if _, err := utils.ExecGit("checkout", "-b", "empty"); err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+
+	if _, err := llm.BranchDraftInput("main"); err == nil {
+		t.Error("Expected an error for a branch with no new commits")
+	}
+}
@@ -0,0 +1,213 @@
+// test/llm/commit_message/commit_message_test.go
+package commit_message_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// promptClient records the last prompt and replies with response, or blocks until
+// the context ends when block is set.
+type promptClient struct {
+	response string
+	block    bool
+	prompt   string
+}
+
+func (c *promptClient) GetChatResponse(ctx context.Context, messages []contextpkg.Message) (string, error) {
+	c.prompt = messages[len(messages)-1].Content
+	if c.block {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return c.response, nil
+}
+
+func (c *promptClient) StreamChatResponse(ctx context.Context, messages []contextpkg.Message) (<-chan string, error) {
+	resp, err := c.GetChatResponse(ctx, messages)
+	ch := make(chan string, 1)
+	ch <- resp
+	close(ch)
+	return ch, err
+}
+
+// ollamaTags serves /api/tags so ProbeLLM finds the LLM server up.
+func ollamaTags(t *testing.T) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"models": []map[string]any{{"name": "test-model"}}})
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("PRBUDDY_LLM_PROVIDER", "ollama")
+	t.Setenv("PRBUDDY_LLM_ENDPOINT", server.URL)
+}
+
+func stage(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	if _, err := utils.ExecGit("add", path); err != nil {
+		t.Fatalf("Failed to stage %s: %v", path, err)
+	}
+}
+
+func TestFormatCommitMessage(t *testing.T) {
+	raw := "```\nSubject: feat(cache): add an in-memory widget cache so repeated lookups are served without a round trip.\n\n" +
+		"Widgets are now cached after the first lookup and evicted whenever the store reports an update for the same id.\n" +
+		"- first item that is long enough to need wrapping onto a second line of text\n\n" +
+		"BREAKING CHANGE: Get returns a copy\n```"
+	got := llm.FormatCommitMessage(raw)
+
+	lines := strings.Split(got, "\n")
+	if !strings.HasPrefix(lines[0], "feat(cache): add an in-memory widget cache") || strings.HasSuffix(lines[0], ".") {
+		t.Errorf("Unexpected subject %q", lines[0])
+	}
+	if lines[1] != "" {
+		t.Errorf("Expected a blank line after the subject, got %q", lines[1])
+	}
+	for _, line := range lines {
+		if len(line) > 72 {
+			t.Errorf("Line longer than 72 columns: %q", line)
+		}
+	}
+	if !strings.Contains(got, "\n- first item that is long enough to need wrapping onto a second line of\n  text\n") {
+		t.Errorf("Expected list item with hanging indent, got:\n%s", got)
+	}
+	if !strings.HasSuffix(got, "\n\nBREAKING CHANGE: Get returns a copy") {
+		t.Errorf("Expected footer kept as its own paragraph, got:\n%s", got)
+	}
+}
+
+func TestFormatCommitMessage_KeepsTrailers(t *testing.T) {
+	raw := "feat(api)!: drop v0 endpoints\n\n" +
+		"Remove the old endpoints.\n" +
+		"BREAKING CHANGE: v0 clients must upgrade\n" +
+		"Refs: #42\n" +
+		"Signed-off-by: Jane Doe <jane@example.com>"
+	want := "feat(api)!: drop v0 endpoints\n\n" +
+		"Remove the old endpoints.\n\n" +
+		"BREAKING CHANGE: v0 clients must upgrade\n" +
+		"Refs: #42\n" +
+		"Signed-off-by: Jane Doe <jane@example.com>"
+	if got := llm.FormatCommitMessage(raw); got != want {
+		t.Errorf("FormatCommitMessage() =\n%s\nwant\n%s", got, want)
+	}
+
+}
+
+func TestFormatCommitMessage_FooterLikeProseIsWrapped(t *testing.T) {
+	note := "Note: the cache is now shared between all requests of a session, so entries written by one request are visible to the next one."
+	raw := "fix(cache): share entries\n\n" + note + "\n\nCloses #7"
+	got := llm.FormatCommitMessage(raw)
+
+	for _, line := range strings.Split(got, "\n") {
+		if len(line) > 72 {
+			t.Errorf("Line longer than 72 columns: %q", line)
+		}
+	}
+	if !strings.HasPrefix(got, "fix(cache): share entries\n\nNote: the cache is now shared") || !strings.HasSuffix(got, "next one.\n\nCloses #7") {
+		t.Errorf("Unexpected message:\n%s", got)
+	}
+
+	// A footer-like line in the middle of a paragraph is prose too
+	got = llm.FormatCommitMessage("fix: x\n\nFirst line\nPreviously: it did y\nand more.")
+	if got != "fix: x\n\nFirst line Previously: it did y and more." {
+		t.Errorf("Unexpected message:\n%s", got)
+	}
+}
+
+func TestGenerateCommitMessage_FollowsConventionalHistory(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	t.Setenv("PRBUDDY_LLM_NUM_CTX", "8192")
+	ollamaTags(t)
+	contextpkg.SetActiveModel("test-model")
+
+	for _, msg := range []string{"feat: add a", "fix(dce): handle b"} {
+		if _, err := utils.ExecGit("commit", "--allow-empty", "-m", msg); err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+	}
+
+	client := &promptClient{response: "feat(readme): describe setup"}
+	llm.SetLLMClient(client)
+
+	if _, err := llm.GenerateCommitMessage(context.Background()); !errors.Is(err, llm.ErrNothingStaged) {
+		t.Errorf("Expected ErrNothingStaged without staged changes, got %v", err)
+	}
+
+	stage(t, "README.md", "# Test Repository\nSetup steps.\n")
+	msg, err := llm.GenerateCommitMessage(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage failed: %v", err)
+	}
+	if msg != "feat(readme): describe setup" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	for _, want := range []string{"Conventional Commits", "fix(dce): handle b", "+Setup steps."} {
+		if !strings.Contains(client.prompt, want) {
+			t.Errorf("Expected prompt to contain %q, got:\n%s", want, client.prompt)
+		}
+	}
+
+	t.Setenv("PRBUDDY_COMMIT_CONVENTION", "plain")
+	if _, err := llm.GenerateCommitMessage(context.Background()); err != nil {
+		t.Fatalf("GenerateCommitMessage failed: %v", err)
+	}
+	if strings.Contains(client.prompt, "Conventional Commits") {
+		t.Error("Expected PRBUDDY_COMMIT_CONVENTION=plain to drop the convention")
+	}
+}
+
+func TestGenerateCommitMessage_GivesUpAtDeadline(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	t.Setenv("PRBUDDY_LLM_NUM_CTX", "8192")
+	t.Setenv("PRBUDDY_COMMIT_MSG_TIMEOUT", "50ms")
+	ollamaTags(t)
+	contextpkg.SetActiveModel("test-model")
+	llm.SetLLMClient(&promptClient{block: true})
+
+	stage(t, "README.md", "changed\n")
+	if _, err := llm.GenerateCommitMessage(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline error, got %v", err)
+	}
+}
+
+func TestGenerateCommitMessage_FailsFastWhenUnreachable(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	t.Setenv("PRBUDDY_LLM_NUM_CTX", "8192")
+	ollamaTags(t)
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	t.Setenv("PRBUDDY_LLM_ENDPOINT", server.URL)
+	client := &promptClient{response: "feat: never asked"}
+	llm.SetLLMClient(client)
+
+	stage(t, "README.md", "changed\n")
+	start := time.Now()
+	_, err := llm.GenerateCommitMessage(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "unreachable") {
+		t.Fatalf("Expected an unreachable error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected to fail at once, took %s", elapsed)
+	}
+	if client.prompt != "" {
+		t.Error("Expected the LLM not to be asked")
+	}
+}
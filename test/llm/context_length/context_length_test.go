@@ -0,0 +1,106 @@
+// test/llm/context_length/context_length_test.go
+package context_length_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+)
+
+// ollamaShow serves /api/show with the given parameters and model_info, counting requests.
+func ollamaShow(t *testing.T, parameters string, modelInfo map[string]any) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/show" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&calls, 1)
+		json.NewEncoder(w).Encode(map[string]any{"parameters": parameters, "model_info": modelInfo})
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("PRBUDDY_LLM_PROVIDER", "ollama")
+	t.Setenv("PRBUDDY_LLM_ENDPOINT", server.URL)
+	t.Setenv("PRBUDDY_LLM_NUM_CTX", "")
+	t.Setenv("PRBUDDY_LLM_MAX_CTX", "")
+	return server, &calls
+}
+
+func TestModelContextLength_FromAPIShow(t *testing.T) {
+	_, calls := ollamaShow(t, "", map[string]any{"qwen3.context_length": 16384})
+
+	if got := llm.ModelContextLength(context.Background(), "ctx-test-show"); got != 16384 {
+		t.Errorf("ModelContextLength = %d, want 16384", got)
+	}
+	// Cached per model
+	llm.ModelContextLength(context.Background(), "ctx-test-show")
+	if n := atomic.LoadInt32(calls); n != 1 {
+		t.Errorf("Expected one /api/show request, got %d", n)
+	}
+}
+
+func TestModelContextLength_ModelfileNumCtxAndCap(t *testing.T) {
+	ollamaShow(t, "stop \"<|im_end|>\"\nnum_ctx 12288", map[string]any{"llama.context_length": 131072})
+	if got := llm.ModelContextLength(context.Background(), "ctx-test-modelfile"); got != 12288 {
+		t.Errorf("Expected the Modelfile num_ctx, got %d", got)
+	}
+
+	ollamaShow(t, "", map[string]any{"llama.context_length": 131072})
+	if got := llm.ModelContextLength(context.Background(), "ctx-test-capped"); got != 32768 {
+		t.Errorf("Expected the default cap of 32768, got %d", got)
+	}
+	t.Setenv("PRBUDDY_LLM_MAX_CTX", "65536")
+	if got := llm.ModelContextLength(context.Background(), "ctx-test-capped-env"); got != 65536 {
+		t.Errorf("Expected PRBUDDY_LLM_MAX_CTX as the cap, got %d", got)
+	}
+}
+
+func TestModelContextLength_NumCtxOverride(t *testing.T) {
+	_, calls := ollamaShow(t, "", map[string]any{"qwen3.context_length": 16384})
+	t.Setenv("PRBUDDY_LLM_NUM_CTX", "4096")
+
+	if got := llm.ModelContextLength(context.Background(), "ctx-test-override"); got != 4096 {
+		t.Errorf("ModelContextLength = %d, want 4096", got)
+	}
+	if n := atomic.LoadInt32(calls); n != 0 {
+		t.Errorf("Expected no lookup with PRBUDDY_LLM_NUM_CTX set, got %d requests", n)
+	}
+}
+
+func TestModelContextLength_FallbackWhenUnreachable(t *testing.T) {
+	server, _ := ollamaShow(t, "", nil)
+	server.Close()
+
+	if got := llm.ModelContextLength(context.Background(), "ctx-test-down"); got != 8192 {
+		t.Errorf("Expected the 8192 fallback, got %d", got)
+	}
+}
+
+func TestModelContextLength_OpenAIHonoursContext(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": "ctx-test-openai", "max_model_len": 16384}}})
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("PRBUDDY_LLM_PROVIDER", "openai")
+	t.Setenv("PRBUDDY_LLM_ENDPOINT", server.URL)
+	t.Setenv("PRBUDDY_LLM_NUM_CTX", "")
+	t.Setenv("PRBUDDY_LLM_MAX_CTX", "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := llm.ModelContextLength(ctx, "ctx-test-openai"); got != 8192 || atomic.LoadInt32(&calls) != 0 {
+		t.Errorf("Expected the fallback without a request after cancellation, got %d (%d requests)", got, calls)
+	}
+	if got := llm.ModelContextLength(context.Background(), "ctx-test-openai"); got != 16384 {
+		t.Errorf("ModelContextLength = %d, want 16384 from max_model_len", got)
+	}
+}
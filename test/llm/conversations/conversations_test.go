@@ -0,0 +1,149 @@
+// test/llm/conversations/conversations_test.go
+package conversations_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+)
+
+func request(t *testing.T, method, path, body string) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	llm.NewRouter().ServeHTTP(rec, req)
+
+	var decoded map[string]any
+	if strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+			t.Fatalf("%s %s: invalid JSON %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec, decoded
+}
+
+// startConversation adds a conversation to the shared manager, removed when the test ends.
+func startConversation(t *testing.T, id string) *contextpkg.Conversation {
+	t.Helper()
+	conv := contextpkg.ConversationManagerInstance.StartConversation(id, "", false)
+	t.Cleanup(func() { contextpkg.ConversationManagerInstance.RemoveConversation(id) })
+	return conv
+}
+
+func conversationIDs(body map[string]any) []string {
+	var ids []string
+	list, _ := body["conversations"].([]any)
+	for _, c := range list {
+		ids = append(ids, c.(map[string]any)["id"].(string))
+	}
+	return ids
+}
+
+func TestListAndGet(t *testing.T) {
+	conv := startConversation(t, "test-list")
+	conv.AddMessage("user", "hello")
+	conv.AddMessage("assistant", "hi")
+
+	rec, body := request(t, http.MethodGet, "/v1/conversations", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list = %d %s", rec.Code, rec.Body.String())
+	}
+	var found map[string]any
+	for _, c := range body["conversations"].([]any) {
+		if c.(map[string]any)["id"] == "test-list" {
+			found = c.(map[string]any)
+		}
+	}
+	if found == nil || found["messageCount"] != float64(2) || found["ephemeral"] != false {
+		t.Errorf("unexpected summary %v", found)
+	}
+
+	rec, body = request(t, http.MethodGet, "/v1/conversations/test-list", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get = %d %s", rec.Code, rec.Body.String())
+	}
+	messages, _ := body["messages"].([]any)
+	if len(messages) != 2 || messages[1].(map[string]any)["content"] != "hi" {
+		t.Errorf("unexpected messages %v", body["messages"])
+	}
+	if tasks, ok := body["tasks"].([]any); !ok || len(tasks) != 0 {
+		t.Errorf("Expected an empty task list, got %v", body["tasks"])
+	}
+}
+
+func TestRenameTagAndFilter(t *testing.T) {
+	startConversation(t, "test-tagged")
+	startConversation(t, "test-untagged")
+
+	rec, body := request(t, http.MethodPatch, "/v1/conversations/test-tagged", `{"title": "Lexer", "tags": ["review"]}`)
+	if rec.Code != http.StatusOK || body["title"] != "Lexer" {
+		t.Fatalf("patch = %d %v", rec.Code, body)
+	}
+
+	// Fields left out stay as they are
+	rec, body = request(t, http.MethodPatch, "/v1/conversations/test-tagged", `{"tags": ["review", "parser"]}`)
+	if rec.Code != http.StatusOK || body["title"] != "Lexer" || len(body["tags"].([]any)) != 2 {
+		t.Errorf("patch tags = %d %v", rec.Code, body)
+	}
+
+	_, body = request(t, http.MethodGet, "/v1/conversations?tag=parser", "")
+	if ids := conversationIDs(body); len(ids) != 1 || ids[0] != "test-tagged" {
+		t.Errorf("Expected only test-tagged, got %v", ids)
+	}
+}
+
+func TestExport(t *testing.T) {
+	conv := startConversation(t, "test-export")
+	conv.AddMessage("user", "What changed?")
+	contextpkg.ConversationManagerInstance.Rename("test-export", "Release notes")
+
+	rec, _ := request(t, http.MethodGet, "/v1/conversations/test-export/export", "")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/markdown") {
+		t.Fatalf("markdown export = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Header().Get("Content-Disposition"), `filename="test-export.md"`) {
+		t.Errorf("Content-Disposition = %q", rec.Header().Get("Content-Disposition"))
+	}
+	if md := rec.Body.String(); !strings.HasPrefix(md, "# Release notes\n") || !strings.Contains(md, "## User\n\nWhat changed?\n") {
+		t.Errorf("unexpected markdown:\n%s", md)
+	}
+
+	rec, body := request(t, http.MethodGet, "/v1/conversations/test-export/export?format=json", "")
+	if rec.Code != http.StatusOK || body["id"] != "test-export" || len(body["messages"].([]any)) != 1 {
+		t.Errorf("json export = %d %v", rec.Code, body)
+	}
+
+	rec, body = request(t, http.MethodGet, "/v1/conversations/test-export/export?format=pdf", "")
+	if rec.Code != http.StatusBadRequest || body["code"] != llm.CodeInvalidRequest {
+		t.Errorf("pdf export = %d %v", rec.Code, body)
+	}
+}
+
+func TestDeleteAndNotFound(t *testing.T) {
+	startConversation(t, "test-delete")
+
+	rec, body := request(t, http.MethodDelete, "/v1/conversations/test-delete", "")
+	if rec.Code != http.StatusOK || body["status"] != "deleted" {
+		t.Errorf("delete = %d %v", rec.Code, body)
+	}
+	if _, exists := contextpkg.ConversationManagerInstance.GetConversation("test-delete"); exists {
+		t.Error("Expected test-delete to be gone")
+	}
+
+	for _, tt := range []struct{ method, path, body string }{
+		{http.MethodGet, "/v1/conversations/test-delete", ""},
+		{http.MethodDelete, "/v1/conversations/test-delete", ""},
+		{http.MethodPatch, "/v1/conversations/test-delete", `{"title": "x"}`},
+		{http.MethodGet, "/v1/conversations/test-delete/export", ""},
+	} {
+		rec, body := request(t, tt.method, tt.path, tt.body)
+		if rec.Code != http.StatusNotFound || body["code"] != llm.CodeNotFound {
+			t.Errorf("%s %s = %d %v, want 404 not_found", tt.method, tt.path, rec.Code, body)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// test/llm/janitor/janitor_test.go
+package janitor_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("PRBUDDY_CONVERSATION_MAX_IDLE", "10m")
+	t.Setenv("PRBUDDY_CONVERSATION_MAX_MESSAGES", "100")
+	t.Setenv("PRBUDDY_CONVERSATION_MAX_BYTES", "nope")
+	t.Setenv("PRBUDDY_PERSIST_EVICTED", "true")
+
+	cfg := llm.JanitorConfigFromEnv()
+	if cfg.Policy.MaxIdle != 10*time.Minute || cfg.Policy.MaxMessages != 100 || !cfg.Persist {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if cfg.Policy.MaxBytes <= 0 || cfg.Interval <= 0 {
+		t.Errorf("Expected defaults for the rest, got %+v", cfg)
+	}
+}
+
+func TestPersistEvicted(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	cm := contextpkg.NewConversationManager()
+	persistent := cm.StartConversation("persistent-1", "", false)
+	persistent.AddMessage("user", "keep me")
+	persistent.LastActivity = time.Now().Add(-2 * time.Hour)
+	cm.StartConversation("ephemeral-1", "", true).LastActivity = time.Now().Add(-2 * time.Hour)
+
+	evicted := llm.ExpireConversations(cm, llm.JanitorConfig{
+		Policy:  contextpkg.ExpiryPolicy{MaxIdle: time.Hour},
+		Persist: true,
+	})
+	if len(evicted) != 2 || cm.Count() != 0 {
+		t.Fatalf("Expected both conversations evicted, got %+v", evicted)
+	}
+
+	dir := filepath.Join(repoPath, ".git", "pr_buddy_db", "conversations")
+	data, err := os.ReadFile(filepath.Join(dir, "persistent-1.json"))
+	if err != nil {
+		t.Fatalf("Expected the persistent conversation saved: %v", err)
+	}
+	var saved llm.ConversationResponse
+	if err := json.Unmarshal(data, &saved); err != nil || saved.ID != "persistent-1" || len(saved.Messages) != 1 {
+		t.Errorf("unexpected saved conversation %s (%v)", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ephemeral-1.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected the ephemeral conversation not to be saved, got %v", err)
+	}
+}
+
+func TestJanitorSweepsUntilStopped(t *testing.T) {
+	cm := contextpkg.NewConversationManager()
+	cm.StartConversation("idle", "", true).LastActivity = time.Now().Add(-time.Hour)
+
+	janitor := llm.StartJanitor(cm, llm.JanitorConfig{
+		Interval: 10 * time.Millisecond,
+		Policy:   contextpkg.ExpiryPolicy{MaxIdle: time.Minute},
+	})
+	deadline := time.Now().Add(2 * time.Second)
+	for cm.Count() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if cm.Count() != 0 {
+		t.Error("Expected the janitor to evict the idle conversation")
+	}
+
+	janitor.Stop()
+	janitor.Stop()
+	cm.StartConversation("late", "", true).LastActivity = time.Now().Add(-time.Hour)
+	time.Sleep(50 * time.Millisecond)
+	if cm.Count() != 1 {
+		t.Error("Expected no sweeps after Stop")
+	}
+}
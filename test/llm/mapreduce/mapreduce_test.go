@@ -0,0 +1,167 @@
+// test/llm/mapreduce/mapreduce_test.go
+package mapreduce_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/utils"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// recordingClient answers summary prompts with a bullet and anything else with a draft.
+type recordingClient struct {
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (c *recordingClient) GetChatResponse(_ context.Context, messages []contextpkg.Message) (string, error) {
+	prompt := messages[len(messages)-1].Content
+	c.mu.Lock()
+	c.prompts = append(c.prompts, prompt)
+	c.mu.Unlock()
+
+	if strings.Contains(prompt, "reviewing one part of a larger commit") {
+		return "- summary for a group", nil
+	}
+	return `{"title": "Draft title", "summary": "Body", "changes": ["one"], "testing": "", "risks": "None", "breaking": [], "labels": []}`, nil
+}
+
+func (c *recordingClient) StreamChatResponse(ctx context.Context, messages []contextpkg.Message) (<-chan string, error) {
+	resp, err := c.GetChatResponse(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan string, 1)
+	ch <- resp
+	close(ch)
+	return ch, nil
+}
+
+func fileDiff(path string, lines int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n@@ -1,0 +1,%d @@\n", path, path, path, path, lines)
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&b, "+line %d of %s with enough text to make the diff expensive\n", i, path)
+	}
+	return b.String()
+}
+
+func setup(t *testing.T) *recordingClient {
+	t.Helper()
+	t.Setenv("PRBUDDY_LLM_NUM_CTX", "2048")
+	contextpkg.SetActiveModel("test-model")
+	client := &recordingClient{}
+	llm.SetLLMClient(client)
+	return client
+}
+
+func TestGenerateDraft_DirectWhenDiffFits(t *testing.T) {
+	client := setup(t)
+
+	result, err := llm.GenerateDraft(context.Background(), llm.DraftInput{
+		CommitMessage: "feat: small change",
+		Diff:          fileDiff("main.go", 3),
+	})
+	if err != nil {
+		t.Fatalf("GenerateDraft failed: %v", err)
+	}
+	if result.Mode != llm.DraftModeDirect {
+		t.Errorf("Expected direct mode, got %s", result.Mode)
+	}
+	if result.Structured == nil || !strings.HasPrefix(result.Draft, "# feat: Draft title") {
+		t.Errorf("Expected a structured draft rendered to markdown, got %q", result.Draft)
+	}
+	if len(client.prompts) != 1 {
+		t.Errorf("Expected a single LLM call, got %d", len(client.prompts))
+	}
+}
+
+func TestGenerateDraft_MapReduceWhenDiffExceedsBudget(t *testing.T) {
+	client := setup(t)
+
+	var diff strings.Builder
+	for i := 0; i < 4; i++ {
+		diff.WriteString(fileDiff(fmt.Sprintf("pkg/file%d.go", i), 40))
+	}
+
+	result, err := llm.GenerateDraft(context.Background(), llm.DraftInput{
+		CommitMessage: "refactor: everything",
+		Diff:          diff.String(),
+	})
+	if err != nil {
+		t.Fatalf("GenerateDraft failed: %v", err)
+	}
+	if result.Mode != llm.DraftModeMapReduce {
+		t.Fatalf("Expected map-reduce mode, got %s", result.Mode)
+	}
+	if len(result.FileSummaries) < 2 {
+		t.Fatalf("Expected several file summaries, got %d", len(result.FileSummaries))
+	}
+
+	var covered []string
+	for _, s := range result.FileSummaries {
+		covered = append(covered, s.Files...)
+	}
+	if len(covered) != 4 {
+		t.Errorf("Expected all 4 files to be summarised, got %v", covered)
+	}
+
+	final := client.prompts[len(client.prompts)-1]
+	if !strings.Contains(final, "Summarised Code Changes") || !strings.Contains(final, "- summary for a group") {
+		t.Errorf("Expected the final prompt to be built from the summaries, got:\n%s", final)
+	}
+	if len(client.prompts) != len(result.FileSummaries)+1 {
+		t.Errorf("Expected %d calls, got %d", len(result.FileSummaries)+1, len(client.prompts))
+	}
+}
+
+func TestCommitDraftInput_RootAndMergeCommits(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	// The test repository starts with a single root commit
+	in, err := llm.CommitDraftInput("HEAD")
+	if err != nil {
+		t.Fatalf("CommitDraftInput failed on the root commit: %v", err)
+	}
+	if strings.TrimSpace(in.CommitMessage) != "Initial commit" || !strings.Contains(in.Diff, "+++ b/README.md") {
+		t.Errorf("Expected the root commit's files as additions, got:\n%s", in.Diff)
+	}
+
+	for _, args := range [][]string{
+		{"checkout", "-q", "-b", "side"},
+		{"commit", "-q", "--allow-empty", "-m", "side"},
+		{"checkout", "-q", "-"},
+	} {
+		if _, err := utils.ExecGit(args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	if err := os.WriteFile("README.md", []byte("# Changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"commit", "-q", "-am", "change readme"},
+		{"merge", "-q", "--no-ff", "-m", "merge side", "side"},
+	} {
+		if _, err := utils.ExecGit(args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	// A merge is diffed against its first parent, as before
+	in, err = llm.CommitDraftInput("HEAD")
+	if err != nil {
+		t.Fatalf("CommitDraftInput failed on the merge: %v", err)
+	}
+	if strings.Contains(in.Diff, "README.md") {
+		t.Errorf("Expected only the merged branch's changes, got:\n%s", in.Diff)
+	}
+}
@@ -0,0 +1,196 @@
+// test/llm/openai_client/openai_client_test.go
+package openai_client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+)
+
+// newStandInServer fakes the subset of the OpenAI API served by llama.cpp and vLLM.
+func newStandInServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/models", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Expected bearer auth header, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"qwen2.5-coder","object":"model"},{"id":"llama3","object":"model"}]}`)
+	})
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model    string `json:"model"`
+			Stream   bool   `json:"stream"`
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if req.Model != "qwen2.5-coder" {
+			t.Errorf("Expected model 'qwen2.5-coder', got %q", req.Model)
+		}
+		if len(req.Messages) != 2 || req.Messages[1].Content != "hello" {
+			t.Errorf("Unexpected messages: %+v", req.Messages)
+		}
+
+		if !req.Stream {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"Hi there"},"finish_reason":"stop"}]}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, piece := range []string{"Hi", " there", "!"} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", piece)
+		}
+		fmt.Fprint(w, ": keep-alive comment\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func setupOpenAIEnv(t *testing.T, url string) {
+	t.Helper()
+	t.Setenv("PRBUDDY_LLM_PROVIDER", "openai")
+	t.Setenv("PRBUDDY_LLM_ENDPOINT", url+"/v1")
+	t.Setenv("PRBUDDY_LLM_API_KEY", "secret")
+	contextpkg.SetActiveModel("")
+	t.Cleanup(func() { contextpkg.SetActiveModel("") })
+}
+
+func testMessages() []contextpkg.Message {
+	return []contextpkg.Message{
+		{Role: "system", Content: "You are a helpful assistant."},
+		{Role: "user", Content: "hello"},
+	}
+}
+
+func TestGetLLMConfig_DetectsModelFromOpenAIServer(t *testing.T) {
+	server := newStandInServer(t)
+	defer server.Close()
+	setupOpenAIEnv(t, server.URL)
+
+	model, endpoint := llm.GetLLMConfig()
+	if model != "qwen2.5-coder" {
+		t.Errorf("Expected first listed model to be auto-detected, got %q", model)
+	}
+	if endpoint != server.URL+"/v1" {
+		t.Errorf("Expected endpoint %q, got %q", server.URL+"/v1", endpoint)
+	}
+}
+
+func TestOpenAIClient_GetChatResponse(t *testing.T) {
+	server := newStandInServer(t)
+	defer server.Close()
+	setupOpenAIEnv(t, server.URL)
+
+	client := &llm.OpenAIClient{}
+	resp, err := client.GetChatResponse(context.Background(), testMessages())
+	if err != nil {
+		t.Fatalf("GetChatResponse failed: %v", err)
+	}
+	if resp != "Hi there" {
+		t.Errorf("Expected 'Hi there', got %q", resp)
+	}
+}
+
+func TestOpenAIClient_StreamChatResponse(t *testing.T) {
+	server := newStandInServer(t)
+	defer server.Close()
+	setupOpenAIEnv(t, server.URL)
+
+	client := &llm.OpenAIClient{}
+	stream, err := client.StreamChatResponse(context.Background(), testMessages())
+	if err != nil {
+		t.Fatalf("StreamChatResponse failed: %v", err)
+	}
+
+	var chunks []string
+	for chunk := range stream {
+		chunks = append(chunks, chunk)
+	}
+	if got := strings.Join(chunks, ""); got != "Hi there!" {
+		t.Errorf("Expected streamed content 'Hi there!', got %q", got)
+	}
+	if len(chunks) != 3 {
+		t.Errorf("Expected 3 chunks, got %d: %v", len(chunks), chunks)
+	}
+}
+
+func TestOpenAIClient_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	setupOpenAIEnv(t, server.URL)
+	contextpkg.SetActiveModel("qwen2.5-coder")
+
+	client := &llm.OpenAIClient{}
+	if _, err := client.GetChatResponse(context.Background(), testMessages()); err == nil {
+		t.Error("Expected error for 503 response, got nil")
+	}
+}
+
+func TestOpenAIClient_StreamStopsOnCancel(t *testing.T) {
+	serverDone := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(serverDone)
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		// Stream forever until the client hangs up
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+				fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"tick\"}}]}\n\n")
+				flusher.Flush()
+			}
+		}
+	}))
+	defer server.Close()
+	setupOpenAIEnv(t, server.URL)
+	contextpkg.SetActiveModel("qwen2.5-coder")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &llm.OpenAIClient{}
+	stream, err := client.StreamChatResponse(ctx, testMessages())
+	if err != nil {
+		t.Fatalf("StreamChatResponse failed: %v", err)
+	}
+
+	<-stream
+	cancel()
+
+	// The channel must close promptly once the context is cancelled
+	timeout := time.After(2 * time.Second)
+	for open := true; open; {
+		select {
+		case _, open = <-stream:
+		case <-timeout:
+			t.Fatal("Stream did not close after context cancellation")
+		}
+	}
+
+	select {
+	case <-serverDone:
+	case <-time.After(2 * time.Second):
+		t.Error("Server request was not cancelled")
+	}
+}
@@ -0,0 +1,81 @@
+// test/llm/pr_conversation/pr_conversation_test.go
+package pr_conversation_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// scriptedClient returns its responses in order (an empty one fails) and records each context.
+type scriptedClient struct {
+	responses []string
+	contexts  [][]contextpkg.Message
+}
+
+func (c *scriptedClient) GetChatResponse(_ context.Context, messages []contextpkg.Message) (string, error) {
+	c.contexts = append(c.contexts, messages)
+	resp := c.responses[0]
+	c.responses = c.responses[1:]
+	if resp == "" {
+		return "", errors.New("server unavailable")
+	}
+	return resp, nil
+}
+
+func (c *scriptedClient) StreamChatResponse(ctx context.Context, messages []contextpkg.Message) (<-chan string, error) {
+	resp, err := c.GetChatResponse(ctx, messages)
+	ch := make(chan string, 1)
+	ch <- resp
+	close(ch)
+	return ch, err
+}
+
+func TestRefineSavedDraft(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	t.Setenv("PRBUDDY_LLM_NUM_CTX", "8192")
+	contextpkg.SetActiveModel("test-model")
+
+	client := &scriptedClient{responses: []string{"", "```markdown\n# Shorter title\n\nOne line.\n```"}}
+	llm.SetLLMClient(client)
+
+	id, err := llm.StartPRConversationFromDraft(context.Background(), "feat: add cache", "+cache", "# Add cache\n\nLong text.")
+	if err != nil {
+		t.Fatalf("StartPRConversationFromDraft failed: %v", err)
+	}
+	if len(client.contexts) != 0 {
+		t.Error("Seeding the conversation should not call the LLM")
+	}
+
+	if _, err := llm.ContinuePRConversation(context.Background(), id, "shorter"); err == nil {
+		t.Fatal("Expected the failing request to return an error")
+	}
+	draft, err := llm.ContinuePRConversation(context.Background(), id, "shorter")
+	if err != nil {
+		t.Fatalf("ContinuePRConversation failed: %v", err)
+	}
+	if draft != "# Shorter title\n\nOne line." {
+		t.Errorf("Expected the unfenced revised draft, got %q", draft)
+	}
+
+	// system, draft prompt, saved draft, then only the retried request
+	sent := client.contexts[1]
+	if len(sent) != 4 {
+		t.Fatalf("Expected the failed request to be dropped, got %d messages", len(sent))
+	}
+	if !strings.Contains(sent[1].Content, "feat: add cache") || !strings.Contains(sent[1].Content, "+cache") {
+		t.Errorf("Expected the draft prompt with commit message and diff, got:\n%s", sent[1].Content)
+	}
+	if sent[2].Role != "assistant" || !strings.Contains(sent[2].Content, "# Add cache") {
+		t.Errorf("Expected the saved draft as the assistant's answer, got %+v", sent[2])
+	}
+	if !strings.HasPrefix(sent[3].Content, "shorter") {
+		t.Errorf("Expected the user's request last, got %q", sent[3].Content)
+	}
+}
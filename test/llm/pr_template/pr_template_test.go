@@ -0,0 +1,98 @@
+// test/llm/pr_template/pr_template_test.go
+package pr_template_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/prtemplate"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// scriptedClient returns its responses in order and records the prompts it saw.
+type scriptedClient struct {
+	responses []string
+	seen      [][]contextpkg.Message
+}
+
+func (c *scriptedClient) GetChatResponse(_ context.Context, messages []contextpkg.Message) (string, error) {
+	c.seen = append(c.seen, messages)
+	resp := c.responses[0]
+	if len(c.responses) > 1 {
+		c.responses = c.responses[1:]
+	}
+	return resp, nil
+}
+
+func (c *scriptedClient) StreamChatResponse(ctx context.Context, messages []contextpkg.Message) (<-chan string, error) {
+	resp, _ := c.GetChatResponse(ctx, messages)
+	ch := make(chan string, 1)
+	ch <- resp
+	close(ch)
+	return ch, nil
+}
+
+func setupTemplate(t *testing.T) string {
+	t.Helper()
+	repoPath := test.SetupTestRepository(t)
+	path := filepath.Join(repoPath, ".github", "pull_request_template.md")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create .github: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("## Summary\n\n## Testing\n\n## Risk\n"), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	t.Setenv("PRBUDDY_LLM_NUM_CTX", "8192")
+	contextpkg.SetActiveModel("test-model")
+	return repoPath
+}
+
+func TestGenerateDraftPR_PromptsWithTemplateHeadings(t *testing.T) {
+	repoPath := setupTemplate(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	client := &scriptedClient{responses: []string{"```\n# T\n## Summary\nx\n## Testing\ny\n## Risk\nz\n```"}}
+	llm.SetLLMClient(client)
+
+	if _, err := llm.GenerateDraftPR(context.Background(), "feat: x", "+x"); err != nil {
+		t.Fatalf("GenerateDraftPR failed: %v", err)
+	}
+	prompt := client.seen[0][len(client.seen[0])-1].Content
+	if !strings.Contains(prompt, "Summary, Testing, Risk") {
+		t.Errorf("Expected prompt to require the template headings, got:\n%s", prompt)
+	}
+	if len(client.seen) != 1 {
+		t.Errorf("Complete draft should not be re-prompted, got %d calls", len(client.seen))
+	}
+}
+
+func TestGenerateDraftPR_RepromptsThenFillsPlaceholders(t *testing.T) {
+	repoPath := setupTemplate(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	client := &scriptedClient{responses: []string{
+		"```\n# T\n## Summary\nx\n```",
+		"```\n# T\n## Summary\nx\n## Testing\ny\n```",
+	}}
+	llm.SetLLMClient(client)
+
+	draft, err := llm.GenerateDraftPR(context.Background(), "feat: x", "+x")
+	if err != nil {
+		t.Fatalf("GenerateDraftPR failed: %v", err)
+	}
+	if len(client.seen) != 2 {
+		t.Fatalf("Expected one re-prompt, got %d calls", len(client.seen))
+	}
+	followUp := client.seen[1][len(client.seen[1])-1].Content
+	if !strings.Contains(followUp, "Testing, Risk") {
+		t.Errorf("Expected re-prompt to name the missing sections, got: %s", followUp)
+	}
+	if !strings.Contains(draft, "## Testing\ny") || !strings.Contains(draft, "## Risk\n\n"+prtemplate.Placeholder) {
+		t.Errorf("Expected second answer plus a Risk placeholder, got:\n%s", draft)
+	}
+}
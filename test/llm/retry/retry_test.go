@@ -0,0 +1,160 @@
+// test/llm/retry/retry_test.go
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+)
+
+// flakyClient fails with the queued errors before succeeding.
+type flakyClient struct {
+	failures []error
+	calls    int
+}
+
+func (f *flakyClient) next() error {
+	f.calls++
+	if len(f.failures) == 0 {
+		return nil
+	}
+	err := f.failures[0]
+	f.failures = f.failures[1:]
+	return err
+}
+
+func (f *flakyClient) GetChatResponse(_ context.Context, _ []contextpkg.Message) (string, error) {
+	if err := f.next(); err != nil {
+		return "", err
+	}
+	return "ok", nil
+}
+
+func (f *flakyClient) StreamChatResponse(_ context.Context, _ []contextpkg.Message) (<-chan string, error) {
+	if err := f.next(); err != nil {
+		return nil, err
+	}
+	ch := make(chan string, 1)
+	ch <- "ok"
+	close(ch)
+	return ch, nil
+}
+
+func fastPolicy() llm.RetryPolicy {
+	return llm.RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+}
+
+func TestRetryingClient_RecoversFromTransientFailures(t *testing.T) {
+	inner := &flakyClient{failures: []error{
+		&llm.StatusError{StatusCode: http.StatusServiceUnavailable},
+		fmt.Errorf("dial: %w", syscall.ECONNREFUSED),
+	}}
+	client := llm.NewRetryingClient(inner, fastPolicy())
+
+	resp, err := client.GetChatResponse(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if resp != "ok" || inner.calls != 3 {
+		t.Errorf("Expected 'ok' after 3 calls, got %q after %d", resp, inner.calls)
+	}
+}
+
+func TestRetryingClient_DoesNotRetryClientErrors(t *testing.T) {
+	inner := &flakyClient{failures: []error{&llm.StatusError{StatusCode: http.StatusBadRequest}}}
+	client := llm.NewRetryingClient(inner, fastPolicy())
+
+	if _, err := client.GetChatResponse(context.Background(), nil); err == nil {
+		t.Fatal("Expected 400 to be returned as an error")
+	}
+	if inner.calls != 1 {
+		t.Errorf("Expected a single attempt for a 400, got %d", inner.calls)
+	}
+}
+
+func TestRetryingClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	transient := &llm.StatusError{StatusCode: http.StatusBadGateway}
+	inner := &flakyClient{failures: []error{transient, transient, transient, transient, transient}}
+	client := llm.NewRetryingClient(inner, fastPolicy())
+
+	_, err := client.StreamChatResponse(context.Background(), nil)
+	var statusErr *llm.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("Expected final 502 StatusError, got %v", err)
+	}
+	if inner.calls != 4 {
+		t.Errorf("Expected 4 attempts, got %d", inner.calls)
+	}
+}
+
+func TestRetryingClient_StopsWhenContextCancelled(t *testing.T) {
+	inner := &flakyClient{failures: []error{&llm.StatusError{StatusCode: http.StatusServiceUnavailable}}}
+	policy := llm.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	client := llm.NewRetryingClient(inner, policy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.GetChatResponse(ctx, nil); err == nil {
+		t.Fatal("Expected error when context expires during backoff")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Backoff ignored context cancellation (took %s)", elapsed)
+	}
+}
+
+func TestRetryingClient_FailFastSkipsConnectionRetries(t *testing.T) {
+	refused := fmt.Errorf("dial: %w", syscall.ECONNREFUSED)
+	inner := &flakyClient{failures: []error{refused, refused}}
+	client := llm.NewRetryingClient(inner, fastPolicy())
+
+	if _, err := client.GetChatResponse(llm.WithFailFast(context.Background()), nil); !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Fatalf("Expected the connection error, got %v", err)
+	}
+	if inner.calls != 1 {
+		t.Errorf("Expected a single attempt under WithFailFast, got %d", inner.calls)
+	}
+
+	// Other transient failures are still retried
+	inner = &flakyClient{failures: []error{&llm.StatusError{StatusCode: http.StatusServiceUnavailable}}}
+	client = llm.NewRetryingClient(inner, fastPolicy())
+	if _, err := client.GetChatResponse(llm.WithFailFast(context.Background()), nil); err != nil || inner.calls != 2 {
+		t.Errorf("Expected a 503 to be retried, got %v after %d calls", err, inner.calls)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&llm.StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{&llm.StatusError{StatusCode: http.StatusInternalServerError}, true},
+		{&llm.StatusError{StatusCode: http.StatusNotFound}, false},
+		{fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{context.DeadlineExceeded, false},
+		{errors.New("empty response from LLM"), false},
+	}
+	for _, tc := range cases {
+		if got := llm.IsRetryable(tc.err); got != tc.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestRetryPolicy_BackoffIsBounded(t *testing.T) {
+	policy := llm.RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for retry := 0; retry < 10; retry++ {
+		if d := policy.Backoff(retry); d < 0 || d > time.Second {
+			t.Errorf("Backoff(%d) = %s, outside [0, 1s]", retry, d)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// test/llm/stream/stream_test.go
+package stream_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+// chunkedClient streams its chunks one at a time, waiting for release before
+// each after the first, and reports when its context is cancelled.
+type chunkedClient struct {
+	chunks    []string
+	release   chan struct{}
+	cancelled chan struct{}
+	contexts  [][]contextpkg.Message
+}
+
+func (c *chunkedClient) GetChatResponse(_ context.Context, messages []contextpkg.Message) (string, error) {
+	c.contexts = append(c.contexts, messages)
+	return strings.Join(c.chunks, ""), nil
+}
+
+func (c *chunkedClient) StreamChatResponse(ctx context.Context, messages []contextpkg.Message) (<-chan string, error) {
+	c.contexts = append(c.contexts, messages)
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		for i, chunk := range c.chunks {
+			if i > 0 && c.release != nil {
+				select {
+				case <-c.release:
+				case <-ctx.Done():
+					close(c.cancelled)
+					return
+				}
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				close(c.cancelled)
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(llm.SSEHandler(func(ctx context.Context, req llm.QuickAssistRequest, onChunk llm.ChunkFunc) (string, error) {
+		id, _, err := llm.StreamQuickAssist(ctx, req.ConversationID, req.Input, onChunk)
+		return id, err
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// readEvents returns the "event: data" lines of an SSE stream until it ends.
+func readEvents(t *testing.T, resp *http.Response) []string {
+	t.Helper()
+	var events []string
+	var event string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			events = append(events, event+" "+strings.TrimPrefix(line, "data: "))
+		}
+	}
+	return events
+}
+
+func TestQuickAssistStream(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	client := &chunkedClient{chunks: []string{"Hel", "lo\n", "world"}}
+	llm.SetLLMClient(client)
+	server := newServer(t)
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"conversationId": "stream-test", "input": "hi"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	got := readEvents(t, resp)
+	want := []string{
+		`chunk {"content":"Hel"}`,
+		`chunk {"content":"lo\n"}`,
+		`chunk {"content":"world"}`,
+		`done {"conversationId":"stream-test"}`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("events =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	conv, ok := contextpkg.ConversationManagerInstance.GetConversation("stream-test")
+	if !ok {
+		t.Fatal("Expected the conversation to be kept")
+	}
+	last := conv.BuildContext()[len(conv.BuildContext())-1]
+	if last.Role != "assistant" || last.Content != "Hello\nworld" {
+		t.Errorf("Expected the whole answer in the conversation, got %+v", last)
+	}
+}
+
+func TestQuickAssistStream_ErrorEvent(t *testing.T) {
+	llm.SetLLMClient(&chunkedClient{})
+	server := newServer(t)
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"input": ""}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := readEvents(t, resp); len(got) != 1 || got[0] != `error {"code":"invalid_request","error":"no user message provided"}` {
+		t.Errorf("events = %q", got)
+	}
+}
+
+func TestWhatStream_EmptyBody(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+	llm.SetLLMClient(&chunkedClient{})
+	server := httptest.NewServer(llm.NewRouter())
+	t.Cleanup(server.Close)
+
+	// The route takes no request body, per the OpenAPI document
+	resp, err := http.Post(server.URL+"/v1/what/stream", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	got := readEvents(t, resp)
+	if len(got) == 0 || got[len(got)-1] != "done {}" {
+		t.Errorf("events = %q", got)
+	}
+}
+
+func TestQuickAssistStream_DisconnectStopsGeneration(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	client := &chunkedClient{
+		chunks:    []string{"first", "never sent"},
+		release:   make(chan struct{}),
+		cancelled: make(chan struct{}),
+	}
+	llm.SetLLMClient(client)
+	server := newServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader(`{"conversationId": "gone", "input": "hi"}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended before the first chunk: %v", err)
+		}
+		if strings.HasPrefix(line, "data: ") {
+			break
+		}
+	}
+	cancel()
+	resp.Body.Close()
+
+	select {
+	case <-client.cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected generation to stop when the client disconnected")
+	}
+}
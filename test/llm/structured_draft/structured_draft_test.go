@@ -0,0 +1,170 @@
+// test/llm/structured_draft/structured_draft_test.go
+package structured_draft_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/llm"
+	"github.com/soyuz43/prbuddy-go/internal/prdraft"
+)
+
+const validDraft = `{
+  "title": "Add widget cache",
+  "summary": "Caches widgets to avoid repeated lookups.",
+  "changes": ["Add cache", "Invalidate on update"],
+  "testing": "Unit tests for cache hits and misses.",
+  "risks": "Stale reads if invalidation is missed.",
+  "breaking": ["WidgetStore.Get now returns a copy"],
+  "labels": ["enhancement"]
+}`
+
+// schemaClient records whether it was asked for schema-constrained output.
+type schemaClient struct {
+	responses []string
+	calls     int
+	schemas   int
+}
+
+func (c *schemaClient) next() string {
+	resp := c.responses[0]
+	if len(c.responses) > 1 {
+		c.responses = c.responses[1:]
+	}
+	c.calls++
+	return resp
+}
+
+func (c *schemaClient) GetChatResponse(_ context.Context, _ []contextpkg.Message) (string, error) {
+	return c.next(), nil
+}
+
+func (c *schemaClient) GetStructuredResponse(_ context.Context, _ []contextpkg.Message, schema map[string]interface{}) (string, error) {
+	if schema != nil {
+		c.schemas++
+	}
+	return c.next(), nil
+}
+
+func (c *schemaClient) StreamChatResponse(_ context.Context, _ []contextpkg.Message) (<-chan string, error) {
+	ch := make(chan string, 1)
+	ch <- c.next()
+	close(ch)
+	return ch, nil
+}
+
+func TestParseStructuredDraft_AcceptsFencedJSON(t *testing.T) {
+	draft, err := llm.ParseStructuredDraft("Here you go:\n```json\n"+validDraft+"\n```", nil)
+	if err != nil {
+		t.Fatalf("Expected valid draft, got %v", err)
+	}
+	if draft.Title != "Add widget cache" || len(draft.Changes) != 2 {
+		t.Errorf("Unexpected draft: %+v", draft)
+	}
+}
+
+func TestParseStructuredDraft_RejectsInvalid(t *testing.T) {
+	cases := map[string]string{
+		"no json":       "Sure! Here is your PR.",
+		"empty title":   `{"title": "", "summary": "s", "changes": ["c"]}`,
+		"no changes":    `{"title": "t", "summary": "s", "changes": []}`,
+		"missing field": `{"title": "t", "summary": "s", "changes": ["c"], "sections": {"Summary": "x"}}`,
+	}
+	for name, raw := range cases {
+		headings := []string(nil)
+		if name == "missing field" {
+			headings = []string{"Summary", "Testing"}
+		}
+		if _, err := llm.ParseStructuredDraft(raw, headings); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestParseStructuredDraft_TitleLimitCountsCharacters(t *testing.T) {
+	draftWithTitle := func(title string) string {
+		return `{"title": "` + title + `", "summary": "s", "changes": ["c"]}`
+	}
+	// Two bytes per character: within the limit in characters, over it in bytes
+	if _, err := llm.ParseStructuredDraft(draftWithTitle(strings.Repeat("é", prdraft.MaxTitleLength)), nil); err != nil {
+		t.Errorf("Expected a %d-character title to be accepted, got %v", prdraft.MaxTitleLength, err)
+	}
+	if _, err := llm.ParseStructuredDraft(draftWithTitle(strings.Repeat("é", prdraft.MaxTitleLength+1)), nil); err == nil {
+		t.Error("Expected a title over prdraft.MaxTitleLength to be rejected")
+	}
+}
+
+func TestStructuredDraft_Markdown(t *testing.T) {
+	draft, err := llm.ParseStructuredDraft(validDraft, nil)
+	if err != nil {
+		t.Fatalf("ParseStructuredDraft failed: %v", err)
+	}
+	md := draft.Markdown()
+	for _, want := range []string{"# Add widget cache\n", "## Summary", "- Invalidate on update", "## Testing", "## Risks", "## Breaking Changes\n\n- WidgetStore.Get now returns a copy"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected markdown to contain %q, got:\n%s", want, md)
+		}
+	}
+}
+
+func TestStructuredDraft_MarkdownFollowsTemplate(t *testing.T) {
+	raw := `{"title": "T", "summary": "s", "changes": ["c"], "testing": "", "risks": "", "breaking": [], "labels": [],
+		"sections": {"summary": "What and why", "Risk": "Low"}}`
+	draft, err := llm.ParseStructuredDraft(raw, []string{"Summary", "Risk"})
+	if err != nil {
+		t.Fatalf("ParseStructuredDraft failed: %v", err)
+	}
+	if md := draft.Markdown(); md != "# T\n\n## Summary\n\nWhat and why\n\n## Risk\n\nLow" {
+		t.Errorf("Unexpected template markdown:\n%s", md)
+	}
+}
+
+func TestGenerateDraft_RetriesInvalidJSON(t *testing.T) {
+	t.Setenv("PRBUDDY_LLM_NUM_CTX", "8192")
+	contextpkg.SetActiveModel("test-model")
+	client := &schemaClient{responses: []string{`{"title": ""}`, validDraft}}
+	llm.SetLLMClient(client)
+
+	result, err := llm.GenerateDraft(context.Background(), llm.DraftInput{
+		CommitMessage: "feat: cache",
+		Diff:          "diff --git a/w.go b/w.go\n--- a/w.go\n+++ b/w.go\n@@ -1,0 +1,1 @@\n+cache\n",
+	})
+	if err != nil {
+		t.Fatalf("GenerateDraft failed: %v", err)
+	}
+	if client.calls != 2 || client.schemas != 2 {
+		t.Errorf("Expected 2 schema-constrained calls, got %d calls / %d with schema", client.calls, client.schemas)
+	}
+	if result.Structured == nil || result.Structured.Labels[0] != "enhancement" {
+		t.Fatalf("Expected structured result, got %+v", result.Structured)
+	}
+	if !strings.HasPrefix(result.Draft, "# feat: Add widget cache") {
+		t.Errorf("Expected rendered markdown draft, got:\n%s", result.Draft)
+	}
+}
+
+func TestGenerateDraft_AppliesConventionalCommits(t *testing.T) {
+	t.Setenv("PRBUDDY_LLM_NUM_CTX", "8192")
+	contextpkg.SetActiveModel("test-model")
+	llm.SetLLMClient(&schemaClient{responses: []string{validDraft}})
+
+	result, err := llm.GenerateDraft(context.Background(), llm.DraftInput{
+		CommitMessage: "feat(cache): add widget cache",
+		Messages:      []string{"feat(cache): add widget cache\n\nBREAKING CHANGE: widgets are copied", "fix(cache): evict on update"},
+		Diff:          "diff --git a/w.go b/w.go\n--- a/w.go\n+++ b/w.go\n@@ -1,0 +1,1 @@\n+cache\n",
+	})
+	if err != nil {
+		t.Fatalf("GenerateDraft failed: %v", err)
+	}
+	if result.Structured.Title != "feat(cache)!: Add widget cache" {
+		t.Errorf("Unexpected title %q", result.Structured.Title)
+	}
+	if !strings.HasPrefix(result.Draft, "# feat(cache)!: Add widget cache\n\n> [!WARNING]\n> **BREAKING CHANGE**: widgets are copied\n\n## Summary") {
+		t.Errorf("Expected breaking callout under the title, got:\n%s", result.Draft)
+	}
+	if strings.Join(result.Labels, ",") != "breaking-change,bug,enhancement" {
+		t.Errorf("Unexpected labels %v", result.Labels)
+	}
+}
@@ -0,0 +1,56 @@
+// test/prdraft/stack/stack_test.go
+package stack_test
+
+import (
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/prdraft"
+)
+
+var entries = []prdraft.StackEntry{
+	{Title: "feat: parser", URL: "https://github.com/o/r/pull/1"},
+	{Title: "feat: lexer", URL: "https://github.com/o/r/pull/2", Current: true},
+}
+
+const section = `<!-- prbuddy:stack -->
+## Stack
+
+1. [feat: parser](https://github.com/o/r/pull/1)
+2. **feat: lexer** (this PR)
+<!-- /prbuddy:stack -->`
+
+func TestStackSection(t *testing.T) {
+	if got := prdraft.StackSection(entries); got != section {
+		t.Errorf("StackSection =\n%s\nwant\n%s", got, section)
+	}
+	if got := prdraft.StackSection(entries[:1]); got != "" {
+		t.Errorf("Expected no section for a single PR, got %q", got)
+	}
+}
+
+func TestWithStack(t *testing.T) {
+	body := prdraft.WithStack("# Title\n\nBody\n", section)
+	if want := "# Title\n\nBody\n\n" + section + "\n"; body != want {
+		t.Fatalf("WithStack (append) = %q, want %q", body, want)
+	}
+	if prdraft.Stack(body) != section {
+		t.Errorf("Stack = %q", prdraft.Stack(body))
+	}
+	if again := prdraft.WithStack(body, section); again != body {
+		t.Errorf("Expected an up to date body to be unchanged, got %q", again)
+	}
+
+	// Replaced in place, keeping what follows it
+	withTail := body + "\nFooter\n"
+	grown := prdraft.StackSection(append(entries, prdraft.StackEntry{Title: "feat: ast", URL: "https://github.com/o/r/pull/3"}))
+	if got, want := prdraft.WithStack(withTail, grown), "# Title\n\nBody\n\n"+grown+"\n\nFooter\n"; got != want {
+		t.Errorf("WithStack (replace) = %q, want %q", got, want)
+	}
+
+	if got := prdraft.WithStack(body, ""); got != "# Title\n\nBody\n" {
+		t.Errorf("WithStack (remove) = %q", got)
+	}
+	if got := prdraft.WithStack("unchanged\r\n", ""); got != "unchanged\r\n" {
+		t.Errorf("Expected a body without a stack to be unchanged, got %q", got)
+	}
+}
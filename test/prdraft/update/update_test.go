@@ -0,0 +1,69 @@
+// test/prdraft/update/update_test.go
+package update_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/prdraft"
+)
+
+const previous = `# Old title
+
+Old description.
+
+<!-- prbuddy:keep -->
+## Deployment notes
+Run the migration first.
+<!-- /prbuddy:keep -->
+
+## Changes since last update
+
+- fix: typo (abc1234)
+`
+
+func TestKeptSections(t *testing.T) {
+	got := prdraft.KeptSections(previous + "\r\n<!-- prbuddy:keep -->\r\nCloses #12\r\n")
+	want := []string{
+		"<!-- prbuddy:keep -->\n## Deployment notes\nRun the migration first.\n<!-- /prbuddy:keep -->",
+		"<!-- prbuddy:keep -->\nCloses #12\n<!-- /prbuddy:keep -->",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("KeptSections = %q, want %q", got, want)
+	}
+	if got := prdraft.KeptSections("no markers"); len(got) != 0 {
+		t.Errorf("Expected no sections, got %q", got)
+	}
+}
+
+func TestUpdatedBody(t *testing.T) {
+	body := prdraft.UpdatedBody("# New title\n\nNew description.\n", previous, []string{"feat: retry (def5678)"})
+
+	want := `# New title
+
+New description.
+
+<!-- prbuddy:keep -->
+## Deployment notes
+Run the migration first.
+<!-- /prbuddy:keep -->
+
+## Changes since last update
+
+- feat: retry (def5678)
+`
+	if body != want {
+		t.Errorf("UpdatedBody =\n%s\nwant\n%s", body, want)
+	}
+	if strings.Contains(body, "Old description") || strings.Contains(body, "abc1234") {
+		t.Error("Expected the unmarked parts of the previous body to be replaced")
+	}
+}
+
+func TestUpdatedBody_NoChanges(t *testing.T) {
+	body := prdraft.UpdatedBody("# Title\n\nBody", "", nil)
+	if body != "# Title\n\nBody\n" {
+		t.Errorf("UpdatedBody = %q", body)
+	}
+}
@@ -0,0 +1,75 @@
+// test/prdraft/validate/validate_test.go
+package validate_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/prdraft"
+)
+
+const draft = `# Add retry to the uploader
+
+## Summary
+Uploads are retried with backoff.
+
+## Testing
+` + "```sh\ngo test ./...\n```\n"
+
+func TestTitle_FirstHeadingOutsideFences(t *testing.T) {
+	fenced := "```\n# not the title\n```\n\n## Real title\n\nBody"
+	if got := prdraft.Title(fenced); got != "Real title" {
+		t.Errorf("Title = %q, want %q", got, "Real title")
+	}
+	if got := prdraft.Title("just a body"); got != "" {
+		t.Errorf("Title without heading = %q, want empty", got)
+	}
+}
+
+func TestValidate_AcceptsCompleteDraft(t *testing.T) {
+	if problems := prdraft.Validate(draft, []string{"Summary", "testing:"}); len(problems) != 0 {
+		t.Errorf("Expected no problems, got %v", problems)
+	}
+}
+
+func TestValidate_ReportsProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		draft    string
+		required []string
+		want     string
+	}{
+		{"no title", "Some description only", nil, "no title"},
+		{"title deleted", "## Summary\nUploads are retried.", nil, "no title"},
+		{"title below text", "Intro\n\n# Title", nil, "no title"},
+		{"long title", "# " + strings.Repeat("x", prdraft.MaxTitleLength+1), nil, "title is 257 characters"},
+		{"long body", "# Title\n" + strings.Repeat("x", prdraft.MaxBodyLength), nil, "description is"},
+		{"missing section", draft, []string{"Risk"}, `missing PR template section "Risk"`},
+		{"unclosed fence", "# Title\n```go\nfunc main() {}\n", nil, "unclosed code fence"},
+		{"wrapped draft", "```markdown\n" + draft + "```", nil, "wrapped in a code fence"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := prdraft.Validate(tt.draft, tt.required)
+			for _, p := range problems {
+				if strings.Contains(p, tt.want) {
+					return
+				}
+			}
+			t.Errorf("Validate = %v, want a problem containing %q", problems, tt.want)
+		})
+	}
+}
+
+func TestInstructions_RoundTrip(t *testing.T) {
+	edited := prdraft.WithInstructions(draft, []string{"no title"})
+	if !strings.Contains(edited, "- no title") {
+		t.Errorf("Expected problems in the instructions, got:\n%s", edited)
+	}
+	if got := prdraft.StripInstructions(edited); got != strings.TrimSpace(draft) {
+		t.Errorf("StripInstructions = %q, want the original draft", got)
+	}
+	if got := prdraft.StripInstructions(prdraft.WithInstructions("", nil)); got != "" {
+		t.Errorf("Expected an emptied draft to stay empty, got %q", got)
+	}
+}
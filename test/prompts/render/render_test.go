@@ -0,0 +1,124 @@
+// test/prompts/render/render_test.go
+package render_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/contextpkg"
+	"github.com/soyuz43/prbuddy-go/internal/prompts"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+func writeOverride(t *testing.T, repoPath, name, content string) {
+	t.Helper()
+	dir := filepath.Join(repoPath, prompts.OverrideDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create override dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".tmpl"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write override: %v", err)
+	}
+}
+
+func TestRender_DefaultDraftTemplate(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	out, err := prompts.Render(prompts.Draft, prompts.Data{
+		CommitMessage: "feat: add widgets",
+		Diff:          "+widget",
+		DiffHeading:   "Code Changes",
+		Branch:        "feature/widgets",
+		Base:          "main",
+	})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	for _, want := range []string{"feat: add widgets", "**Code Changes:**\n+widget", "feature/widgets (targeting main)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected rendered draft prompt to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRender_WhatTemplateListsTasks(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	out, err := prompts.Render(prompts.What, prompts.Data{
+		Diff:  "+x",
+		Tasks: []contextpkg.Task{{Description: "fix login", Files: []string{"auth.go"}}},
+	})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(out, "- fix login (files: auth.go)") {
+		t.Errorf("Expected DCE task in what prompt, got:\n%s", out)
+	}
+}
+
+func TestRender_UsesRepoOverride(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	writeOverride(t, repoPath, prompts.Draft, "Custom for {{.Branch}}: {{join .Files \",\"}}")
+
+	out, err := prompts.Render(prompts.Draft, prompts.Data{Branch: "dev", Files: []string{"a.go", "b.go"}})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if out != "Custom for dev: a.go,b.go" {
+		t.Errorf("Expected override output, got %q", out)
+	}
+}
+
+func TestHash_ChangesWithOverride(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	embedded := prompts.Hash(prompts.Draft)
+	if len(embedded) != 12 {
+		t.Fatalf("Expected a 12 digit hash, got %q", embedded)
+	}
+	writeOverride(t, repoPath, prompts.Draft, "mine")
+	if prompts.Hash(prompts.Draft) == embedded {
+		t.Error("Expected the override to change the prompt hash")
+	}
+}
+
+func TestRender_BrokenOverrideFallsBackToDefault(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	writeOverride(t, repoPath, prompts.What, "{{.Unclosed")
+
+	out, err := prompts.Render(prompts.What, prompts.Data{Diff: "+y"})
+	if err != nil {
+		t.Fatalf("Expected fallback to default, got error: %v", err)
+	}
+	if !strings.Contains(out, "These are the git diffs") {
+		t.Errorf("Expected default what prompt, got:\n%s", out)
+	}
+}
+
+func TestInit_WritesDefaultsWithoutOverwriting(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	writeOverride(t, repoPath, prompts.Draft, "mine")
+
+	written, err := prompts.Init(false)
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if len(written) != len(prompts.Names())-1 {
+		t.Errorf("Expected %d templates written, got %v", len(prompts.Names())-1, written)
+	}
+	data, _ := os.ReadFile(filepath.Join(repoPath, prompts.OverrideDir, "draft.tmpl"))
+	if string(data) != "mine" {
+		t.Errorf("Existing override was overwritten: %q", data)
+	}
+}
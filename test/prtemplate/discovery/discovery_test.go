@@ -0,0 +1,104 @@
+// test/prtemplate/discovery/discovery_test.go
+package discovery_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/prtemplate"
+	"github.com/soyuz43/prbuddy-go/test"
+)
+
+const template = `## Summary
+<!-- What does this change and why? -->
+
+## Testing
+
+` + "```" + `
+## not a heading
+` + "```" + `
+
+## Risk
+`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestParseHeadings_SkipsCodeAndComments(t *testing.T) {
+	got := prtemplate.ParseHeadings(template + "<!--\n## Hidden\n-->\n")
+	want := []string{"Summary", "Testing", "Risk"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseHeadings = %v, want %v", got, want)
+	}
+}
+
+func TestFind_NoTemplate(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	tmpl, err := prtemplate.Find()
+	if err != nil || tmpl != nil {
+		t.Errorf("Expected no template, got %v (err %v)", tmpl, err)
+	}
+}
+
+func TestFind_GitHubDirCaseInsensitive(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	writeFile(t, filepath.Join(repoPath, ".github", "PULL_REQUEST_TEMPLATE.md"), template)
+
+	tmpl, err := prtemplate.Find()
+	if err != nil || tmpl == nil {
+		t.Fatalf("Expected template, got %v (err %v)", tmpl, err)
+	}
+	if len(tmpl.Headings) != 3 {
+		t.Errorf("Expected 3 headings, got %v", tmpl.Headings)
+	}
+}
+
+func TestFind_MultiTemplateDirectory(t *testing.T) {
+	repoPath := test.SetupTestRepository(t)
+	defer test.CleanupTestRepository(t, repoPath)
+
+	dir := filepath.Join(repoPath, "docs", "PULL_REQUEST_TEMPLATE")
+	writeFile(t, filepath.Join(dir, "bugfix.md"), "## Bug\n## Fix\n")
+	writeFile(t, filepath.Join(dir, "default.md"), template)
+
+	tmpl, err := prtemplate.Find()
+	if err != nil || tmpl == nil || !strings.HasSuffix(tmpl.Path, "default.md") {
+		t.Fatalf("Expected default.md to be chosen, got %+v (err %v)", tmpl, err)
+	}
+
+	t.Setenv("PRBUDDY_PR_TEMPLATE", "bugfix")
+	tmpl, err = prtemplate.Find()
+	if err != nil || tmpl == nil || !reflect.DeepEqual(tmpl.Headings, []string{"Bug", "Fix"}) {
+		t.Fatalf("Expected bugfix.md via PRBUDDY_PR_TEMPLATE, got %+v (err %v)", tmpl, err)
+	}
+}
+
+func TestMissingHeadingsAndFill(t *testing.T) {
+	draft := "# Add widgets\n\n### summary:\nAdds widgets.\n\n## Testing\nUnit tests.\n"
+	missing := prtemplate.MissingHeadings(draft, []string{"Summary", "Testing", "Risk"})
+	if !reflect.DeepEqual(missing, []string{"Risk"}) {
+		t.Fatalf("MissingHeadings = %v, want [Risk]", missing)
+	}
+
+	filled := prtemplate.FillMissing(draft, missing)
+	if !strings.Contains(filled, "## Risk\n\n"+prtemplate.Placeholder) {
+		t.Errorf("Expected placeholder section, got:\n%s", filled)
+	}
+	if len(prtemplate.MissingHeadings(filled, []string{"Summary", "Testing", "Risk"})) != 0 {
+		t.Error("Filled draft should contain every heading")
+	}
+}
@@ -0,0 +1,93 @@
+// test/revisions/store/store_test.go
+package store_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soyuz43/prbuddy-go/internal/revisions"
+)
+
+var files = []string{"draft.md", "draft.json"}
+
+func write(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestSaveAndList(t *testing.T) {
+	dir := t.TempDir()
+	if n, err := revisions.Next(dir); err != nil || n != 1 {
+		t.Fatalf("Expected first revision 1, got %d (%v)", n, err)
+	}
+
+	write(t, dir, "draft.md", "first")
+	write(t, dir, "draft.json", "{}")
+	if err := revisions.Save(dir, 1, files); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	write(t, dir, "draft.md", "second")
+	os.Remove(filepath.Join(dir, "draft.json"))
+	if err := revisions.Save(dir, 2, files); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := revisions.Save(dir, 2, files); err == nil {
+		t.Error("Expected saving an existing revision to fail")
+	}
+
+	revs, err := revisions.List(dir)
+	if err != nil || len(revs) != 2 || revs[0].Number != 1 || revs[1].Number != 2 {
+		t.Fatalf("Unexpected revisions %+v (%v)", revs, err)
+	}
+	if got := read(t, filepath.Join(revisions.Path(dir, 1), "draft.md")); got != "first" {
+		t.Errorf("Expected revision 1 to keep the first draft, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(revisions.Path(dir, 2), "draft.json")); !os.IsNotExist(err) {
+		t.Error("Expected missing files to be skipped")
+	}
+	if n, _ := revisions.Next(dir); n != 3 {
+		t.Errorf("Expected next revision 3, got %d", n)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "draft.md", "first")
+	write(t, dir, "draft.json", "{}")
+	revisions.Save(dir, 1, files)
+	write(t, dir, "draft.md", "second")
+	os.Remove(filepath.Join(dir, "draft.json"))
+	revisions.Save(dir, 2, files)
+
+	if err := revisions.Restore(dir, 1, files); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if got := read(t, filepath.Join(dir, "draft.md")); got != "first" {
+		t.Errorf("Expected restored draft, got %q", got)
+	}
+	if got := read(t, filepath.Join(dir, "draft.json")); got != "{}" {
+		t.Errorf("Expected restored structured draft, got %q", got)
+	}
+
+	if err := revisions.Restore(dir, 2, files); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "draft.json")); !os.IsNotExist(err) {
+		t.Error("Expected files absent from the revision to be removed")
+	}
+	if err := revisions.Restore(dir, 9, files); err == nil {
+		t.Error("Expected restoring a missing revision to fail")
+	}
+}